@@ -0,0 +1,192 @@
+package emailaudit
+
+import (
+	"bufio"
+	"io"
+	"net"
+	"strings"
+	"time"
+	"unicode"
+)
+
+var reportTimeFormats = []string{
+	timeFormat,
+	"2006-01-02 15:04:05",
+	time.RFC3339,
+	"2006/01/02 15:04:05",
+	"2006/01/02 15:04",
+	"2006-01-02",
+}
+
+// AccountInfoReport is the parsed result file of a completed account information request
+type AccountInfoReport struct {
+	EmailAddress        string            `json:"emailAddress"`
+	CreationDate        *time.Time        `json:"creationDate,omitempty"`
+	LastLogin           *time.Time        `json:"lastLogin,omitempty"`
+	Logins              []LoginRecord     `json:"logins"`
+	ForwardingAddresses []string          `json:"forwardingAddresses"`
+	Aliases             []string          `json:"aliases"`
+	POP                 ProtocolStatus    `json:"pop"`
+	IMAP                ProtocolStatus    `json:"imap"`
+	Delegates           []string          `json:"delegates"`
+	Extra               map[string]string `json:"extra,omitempty"`
+}
+
+// LoginRecord LoginRecord
+type LoginRecord struct {
+	Time *time.Time `json:"time,omitempty"`
+	IP   string     `json:"ip"`
+}
+
+// ProtocolStatus ProtocolStatus
+type ProtocolStatus struct {
+	Enabled bool   `json:"enabled"`
+	Status  string `json:"status,omitempty"`
+}
+
+// ParseAccountInfoReport parses a result file of an account information request.
+//
+// The file consists of "Key: Value" lines and sections, which are a "Key:" line
+// followed by one item per line until a blank line or the next key:
+//
+//	Email Address: abhishek@example.com
+//	Last Login: 2009-09-10 08:00
+//	Login IPs:
+//	2009-09-10 08:00 203.0.113.4
+//	POP Status: Enabled
+//	Aliases: abhi@example.com, a@example.com
+//
+// Keys are matched case-insensitively and regardless of spaces.
+// Unrecognised keys are kept in Extra.
+func ParseAccountInfoReport(r io.Reader) (*AccountInfoReport, error) {
+	report := &AccountInfoReport{
+		Logins:              []LoginRecord{},
+		ForwardingAddresses: []string{},
+		Aliases:             []string{},
+		Delegates:           []string{},
+	}
+	section := ""
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			section = ""
+			continue
+		}
+		if idx := strings.Index(line, ":"); idx > 0 && !isReportItem(section, line) {
+			key := normalizeReportKey(line[:idx])
+			value := strings.TrimSpace(line[idx+1:])
+			section = ""
+			if value == "" {
+				section = key
+				continue
+			}
+			report.set(key, line[:idx], value)
+			continue
+		}
+		if section != "" {
+			report.add(section, strings.TrimLeft(line, "-*• \t"))
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+func (report *AccountInfoReport) set(key string, rawKey string, value string) {
+	switch key {
+	case "emailaddress", "email", "user", "useremailaddress":
+		report.EmailAddress = value
+	case "accountcreationdate", "creationdate", "created":
+		report.CreationDate = parseReportTime(value)
+	case "lastlogin", "lastlogintime":
+		report.LastLogin = parseReportTime(value)
+	case "popstatus", "pop":
+		report.POP = parseProtocolStatus(value)
+	case "imapstatus", "imap":
+		report.IMAP = parseProtocolStatus(value)
+	case "loginips", "loginiphistory", "iplogins", "logins",
+		"forwardingaddresses", "forwardingaddress", "forwarding",
+		"aliases", "alias", "emailaliases",
+		"delegates", "delegate", "delegatedaccounts":
+		for _, v := range strings.Split(value, ",") {
+			if v = strings.TrimSpace(v); v != "" {
+				report.add(key, v)
+			}
+		}
+	default:
+		if report.Extra == nil {
+			report.Extra = map[string]string{}
+		}
+		report.Extra[strings.TrimSpace(rawKey)] = value
+	}
+}
+
+func (report *AccountInfoReport) add(section string, item string) {
+	switch section {
+	case "loginips", "loginiphistory", "iplogins", "logins":
+		report.Logins = append(report.Logins, parseLoginRecord(item))
+	case "forwardingaddresses", "forwardingaddress", "forwarding":
+		report.ForwardingAddresses = append(report.ForwardingAddresses, item)
+	case "aliases", "alias", "emailaliases":
+		report.Aliases = append(report.Aliases, item)
+	case "delegates", "delegate", "delegatedaccounts":
+		report.Delegates = append(report.Delegates, item)
+	}
+}
+
+// isReportItem returns true if line is an item of the login section, which contains colons in times and IPv6 addresses
+func isReportItem(section string, line string) bool {
+	switch section {
+	case "loginips", "loginiphistory", "iplogins", "logins":
+		fields := strings.Fields(strings.TrimLeft(line, "-*• \t"))
+		return len(fields) > 0 && (unicode.IsDigit(rune(fields[0][0])) || net.ParseIP(fields[0]) != nil)
+	}
+	return false
+}
+
+func normalizeReportKey(key string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) {
+			return unicode.ToLower(r)
+		}
+		return -1
+	}, key)
+}
+
+func parseLoginRecord(item string) LoginRecord {
+	var rest []string
+	record := LoginRecord{}
+	for _, f := range strings.Fields(item) {
+		if ip := net.ParseIP(strings.Trim(f, "(),;")); ip != nil && record.IP == "" {
+			record.IP = ip.String()
+			continue
+		}
+		rest = append(rest, f)
+	}
+	record.Time = parseReportTime(strings.Join(rest, " "))
+	return record
+}
+
+func parseReportTime(value string) *time.Time {
+	value = strings.TrimSuffix(strings.TrimSpace(value), " UTC")
+	for _, f := range reportTimeFormats {
+		if t, err := time.Parse(f, value); err == nil {
+			return &t
+		}
+	}
+	return nil
+}
+
+func parseProtocolStatus(value string) ProtocolStatus {
+	enabled := false
+	if fields := strings.Fields(strings.ToLower(value)); len(fields) > 0 {
+		switch strings.Trim(fields[0], ".,;()") {
+		case "enabled", "on", "true", "yes":
+			enabled = true
+		}
+	}
+	return ProtocolStatus{Enabled: enabled, Status: value}
+}
@@ -0,0 +1,107 @@
+package emailaudit
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+const accountInfoReport = `Account Information
+Email Address: abhishek@example.com
+Account Creation Date: 2009-01-05 12:00
+Last Login: 2009-09-10 08:00:12
+Quota Used: 1.2 GB
+
+Login IPs:
+2009-09-10 08:00 203.0.113.4
+2009-09-09 17:22:10 198.51.100.23
+- 2001:db8::1 2009-09-08 09:00
+
+Forwarding Addresses:
+boss@example.com
+
+Aliases: abhi@example.com, a@example.com
+
+POP Status: Enabled (for all mail)
+IMAP Status: Disabled
+
+Delegates:
+* namrata@example.com
+* joe@example.com
+`
+
+const accountInfoReportJSON = `{
+  "emailAddress": "abhishek@example.com",
+  "creationDate": "2009-01-05T12:00:00Z",
+  "lastLogin": "2009-09-10T08:00:12Z",
+  "logins": [
+    {
+      "time": "2009-09-10T08:00:00Z",
+      "ip": "203.0.113.4"
+    },
+    {
+      "time": "2009-09-09T17:22:10Z",
+      "ip": "198.51.100.23"
+    },
+    {
+      "time": "2009-09-08T09:00:00Z",
+      "ip": "2001:db8::1"
+    }
+  ],
+  "forwardingAddresses": [
+    "boss@example.com"
+  ],
+  "aliases": [
+    "abhi@example.com",
+    "a@example.com"
+  ],
+  "pop": {
+    "enabled": true,
+    "status": "Enabled (for all mail)"
+  },
+  "imap": {
+    "enabled": false,
+    "status": "Disabled"
+  },
+  "delegates": [
+    "namrata@example.com",
+    "joe@example.com"
+  ],
+  "extra": {
+    "Quota Used": "1.2 GB"
+  }
+}`
+
+const emptyAccountInfoReportJSON = `{
+  "emailAddress": "",
+  "logins": [],
+  "forwardingAddresses": [],
+  "aliases": [],
+  "pop": {
+    "enabled": false
+  },
+  "imap": {
+    "enabled": false
+  },
+  "delegates": []
+}`
+
+func TestParseAccountInfoReport(t *testing.T) {
+	for _, test := range []struct {
+		report   string
+		expected string
+	}{
+		{accountInfoReport, accountInfoReportJSON},
+		{strings.Replace(accountInfoReport, "\n", "\r\n", -1), accountInfoReportJSON},
+		{"", emptyAccountInfoReportJSON},
+	} {
+		report, err := ParseAccountInfoReport(strings.NewReader(test.report))
+		if err != nil {
+			t.Errorf("Expected nil but got %v", err)
+		}
+		actual, _ := json.MarshalIndent(report, "", "  ")
+		if string(actual) != test.expected {
+			t.Errorf(`Expected "%v" but got "%v"`, test.expected, string(actual))
+		}
+	}
+}
@@ -0,0 +1,97 @@
+package emailaudit
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// AccountInfoService AccountInfoService
+type AccountInfoService struct {
+	s *Service
+}
+
+// NewAccountInfoService returns new AccountInfoService
+func NewAccountInfoService(s *Service) *AccountInfoService {
+	rs := &AccountInfoService{s: s}
+	return rs
+}
+
+// Create creates an account information request
+// - https://developers.google.com/admin-sdk/email-audit/#creating_an_account_information_request
+func (svc *AccountInfoService) Create(domainName string, userName string) (*AccountInfo, error) {
+	return svc.CreateContext(context.Background(), domainName, userName)
+}
+
+// CreateContext is Create with a context
+func (svc *AccountInfoService) CreateContext(ctx context.Context, domainName string, userName string) (*AccountInfo, error) {
+	info := AccountInfo{DomainName: domainName, UserName: userName, baseURL: svc.s.baseURL}
+	bytes, err := svc.s.do(ctx, domainName, "POST", info.URL(), monitorWriteProperties{}.toXML())
+	if err != nil {
+		return nil, err
+	}
+	return svc.accountInfoFromXML(bytes)
+}
+
+// Get retrieves an account information request status
+// - https://developers.google.com/admin-sdk/email-audit/#retrieving_an_account_information_request_status
+func (svc *AccountInfoService) Get(domainName string, userName string, requestID string) (*AccountInfo, error) {
+	return svc.GetContext(context.Background(), domainName, userName, requestID)
+}
+
+// GetContext is Get with a context
+func (svc *AccountInfoService) GetContext(ctx context.Context, domainName string, userName string, requestID string) (*AccountInfo, error) {
+	url := fmt.Sprintf("%v/%v/%v/%v", svc.s.endpoint(accountInfoPath), domainName, userName, requestID)
+	bytes, err := svc.s.do(ctx, domainName, "GET", url, nil)
+	if err != nil {
+		return nil, err
+	}
+	return svc.accountInfoFromXML(bytes)
+}
+
+// ListAll retrieves all account information requests in the domain.
+// Requests created before fromDate are omitted unless fromDate is nil.
+// - https://developers.google.com/admin-sdk/email-audit/#retrieving_all_account_information_requests_in_a_domain
+func (svc *AccountInfoService) ListAll(domainName string, fromDate *time.Time) ([]AccountInfo, error) {
+	return svc.ListAllContext(context.Background(), domainName, fromDate)
+}
+
+// ListAllContext is ListAll with a context
+func (svc *AccountInfoService) ListAllContext(ctx context.Context, domainName string, fromDate *time.Time) ([]AccountInfo, error) {
+	url := fmt.Sprintf("%v/%v", svc.s.endpoint(accountInfoPath), domainName)
+	if fromDate != nil {
+		url += "?" + fromDateQuery(fromDate)
+	}
+	entries, err := svc.s.getFeed(ctx, domainName, url)
+	if err != nil {
+		return nil, err
+	}
+	var infos []AccountInfo
+	for _, v := range entries {
+		a := v.toAccountInfo()
+		a.baseURL = svc.s.baseURL
+		infos = append(infos, a)
+	}
+	return infos, nil
+}
+
+// Delete deletes an account information request
+// - https://developers.google.com/admin-sdk/email-audit/#deleting_an_account_information_request
+func (svc *AccountInfoService) Delete(domainName string, userName string, requestID string) error {
+	return svc.DeleteContext(context.Background(), domainName, userName, requestID)
+}
+
+// DeleteContext is Delete with a context
+func (svc *AccountInfoService) DeleteContext(ctx context.Context, domainName string, userName string, requestID string) error {
+	url := fmt.Sprintf("%v/%v/%v/%v", svc.s.endpoint(accountInfoPath), domainName, userName, requestID)
+	_, err := svc.s.do(ctx, domainName, "DELETE", url, nil)
+	return err
+}
+
+func (svc *AccountInfoService) accountInfoFromXML(data []byte) (*AccountInfo, error) {
+	a, err := accountInfoFromXML(data)
+	if a != nil {
+		a.baseURL = svc.s.baseURL
+	}
+	return a, err
+}
@@ -0,0 +1,122 @@
+package emailaudit
+
+import (
+	"testing"
+	"time"
+
+	gock "gopkg.in/h2non/gock.v1"
+)
+
+func TestAccountInfoServiceCreate(t *testing.T) {
+	defer gock.Off()
+	gock.New("https://apps-apis.google.com").
+		Post("/a/feeds/compliance/audit/account/example.com/abhishek").
+		MatchType("application/atom\\+xml").
+		MatchHeader("Authorization", "Bearer test").
+		MatchHeader("User-Agent", "google-api-go-client/0.5").
+		Reply(201).
+		XML(accountInfoXML)
+
+	a, err := newTestService().AccountInfo.Create("example.com", "abhishek")
+	if err != nil {
+		t.Errorf("Expected nil but got %v", err)
+	}
+	_TestAccountInfo(a, t)
+}
+
+func TestAccountInfoServiceGet(t *testing.T) {
+	defer gock.Off()
+	gock.New("https://apps-apis.google.com").
+		Get("/a/feeds/compliance/audit/account/example.com/abhishek/1234567").
+		MatchHeader("Authorization", "Bearer test").
+		MatchHeader("User-Agent", "google-api-go-client/0.5").
+		Reply(200).
+		XML(accountInfoXML)
+
+	a, err := newTestService().AccountInfo.Get("example.com", "abhishek", "1234567")
+	if err != nil {
+		t.Errorf("Expected nil but got %v", err)
+	}
+	_TestAccountInfo(a, t)
+}
+
+func TestAccountInfoServiceListAll(t *testing.T) {
+	defer gock.Off()
+	gock.New("https://apps-apis.google.com").
+		Get("/a/feeds/compliance/audit/account/example.com").
+		MatchParam("fromDate", "2009-09-01 00:00").
+		MatchHeader("Authorization", "Bearer test").
+		MatchHeader("User-Agent", "google-api-go-client/0.5").
+		Reply(200).
+		XML(accountInfosXML)
+
+	fromDate := time.Date(2009, time.September, 1, 0, 0, 0, 0, time.UTC)
+	a, err := newTestService().AccountInfo.ListAll("example.com", &fromDate)
+	if err != nil {
+		t.Errorf("Expected nil but got %v", err)
+	}
+	for _, test := range []struct {
+		actual   interface{}
+		expected interface{}
+	}{
+		{len(a), 2},
+		{a[0].RequestID, "1234567"},
+		{a[0].UserName, "abhishek"},
+		{a[0].Status, CompletedStatus},
+		{a[1].RequestID, "1234568"},
+		{a[1].UserName, "namrata"},
+		{a[1].Status, PendingStatus},
+	} {
+		if test.actual != test.expected {
+			t.Errorf(`Expected "%v" but got "%v"`, test.expected, test.actual)
+		}
+	}
+}
+
+func TestAccountInfoServiceDelete(t *testing.T) {
+	defer gock.Off()
+	gock.New("https://apps-apis.google.com").
+		Delete("/a/feeds/compliance/audit/account/example.com/abhishek/1234567").
+		MatchHeader("Authorization", "Bearer test").
+		MatchHeader("User-Agent", "google-api-go-client/0.5").
+		Reply(200)
+
+	err := newTestService().AccountInfo.Delete("example.com", "abhishek", "1234567")
+	if err != nil {
+		t.Errorf("Expected nil but got %v", err)
+	}
+}
+
+func TestAccountInfoServiceHTTPErrorResponse(t *testing.T) {
+	defer gock.Off()
+	gock.New("https://apps-apis.google.com").
+		Post("/a/feeds/compliance/audit/account/example.com/abhishek").
+		Reply(400).
+		BodyString("Omg")
+	gock.New("https://apps-apis.google.com").
+		Get("/a/feeds/compliance/audit/account/example.com/abhishek/1234567").
+		Reply(400).
+		BodyString("Omg")
+	gock.New("https://apps-apis.google.com").
+		Get("/a/feeds/compliance/audit/account/example.com").
+		Reply(400).
+		BodyString("Omg")
+	gock.New("https://apps-apis.google.com").
+		Delete("/a/feeds/compliance/audit/account/example.com/abhishek/1234567").
+		Reply(400).
+		BodyString("Omg")
+
+	svc := newTestService()
+	created, err1 := svc.AccountInfo.Create("example.com", "abhishek")
+	got, err2 := svc.AccountInfo.Get("example.com", "abhishek", "1234567")
+	list, err3 := svc.AccountInfo.ListAll("example.com", nil)
+	err4 := svc.AccountInfo.Delete("example.com", "abhishek", "1234567")
+	for _, err := range []error{err1, err2, err3, err4} {
+		if err == nil || err.Error() != "400 Bad Request: Omg" {
+			t.Errorf(`Expected "400 Bad Request: Omg" but got "%v"`, err)
+		}
+	}
+	if created != nil || got != nil || list != nil {
+		t.Errorf("Expected nil but got %v %v %v", created, got, list)
+	}
+}
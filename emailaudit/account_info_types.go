@@ -0,0 +1,70 @@
+package emailaudit
+
+import (
+	"encoding/xml"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+const accountInfoPath = "account"
+
+// AccountInfo AccountInfo
+type AccountInfo struct {
+	DomainName        string
+	UserName          string
+	RequestID         string
+	Status            RequestStatus
+	AdminEmailAddress string
+	UserEmailAddress  string
+	RequestDate       *time.Time
+	CompletedDate     *time.Time
+	NumberOfFiles     int
+	FileURLs          []string
+	Updated           *time.Time
+
+	baseURL string
+}
+
+// URL returns URL
+func (req *AccountInfo) URL() string {
+	return fmt.Sprintf("%v/%v/%v", endpoint(req.baseURL, accountInfoPath), req.DomainName, req.UserName)
+}
+
+func accountInfoFromXML(data []byte) (*AccountInfo, error) {
+	var v monitorReadProperties
+	if err := xml.Unmarshal(data, &v); err != nil {
+		return nil, err
+	}
+	a := v.toAccountInfo()
+	return &a, nil
+}
+
+func (m monitorReadProperties) toAccountInfo() AccountInfo {
+	a := AccountInfo{}
+	urls := fileURLs{}
+	for _, p := range m.AppProperties {
+		switch p.Name {
+		case "requestId":
+			a.RequestID = p.Value
+		case "status":
+			a.Status = RequestStatus(p.Value)
+		case "adminEmailAddress":
+			a.AdminEmailAddress = p.Value
+		case "userEmailAddress":
+			a.UserEmailAddress = p.Value
+		case "requestDate":
+			a.RequestDate = parseTime(p.Value)
+		case "completedDate":
+			a.CompletedDate = parseTime(p.Value)
+		case "numberOfFiles":
+			a.NumberOfFiles, _ = strconv.Atoi(p.Value)
+		default:
+			urls.set(p.Name, p.Value)
+		}
+	}
+	a.FileURLs = urls.list(a.NumberOfFiles)
+	a.DomainName, a.UserName = m.domainAndUser(accountInfoPath, a.UserEmailAddress, a.RequestID)
+	a.Updated = m.Updated
+	return a
+}
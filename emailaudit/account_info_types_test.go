@@ -0,0 +1,93 @@
+package emailaudit
+
+import (
+	"testing"
+	"time"
+)
+
+func TestAccountInfoURL(t *testing.T) {
+	a := AccountInfo{
+		UserName:   "abhishek",
+		DomainName: "example.com",
+	}
+	expected := "https://apps-apis.google.com/a/feeds/compliance/audit/account/example.com/abhishek"
+	actual := a.URL()
+	if expected != actual {
+		t.Errorf(`Expected "%v" but got "%v"`, expected, actual)
+	}
+}
+
+const accountInfoXML = `<entry xmlns='http://www.w3.org/2005/Atom' xmlns:apps='http://schemas.google.com/apps/2006'>
+  <id>https://apps-apis.google.com/a/feeds/compliance/audit/account/example.com/abhishek/1234567</id>
+  <updated>2009-09-10T09:16:46.213Z</updated>
+  <link rel='self' type='application/atom+xml' href='https://apps-apis.google.com/a/feeds/compliance/audit/account/example.com/abhishek/1234567' />
+  <link rel='edit' type='application/atom+xml' href='https://apps-apis.google.com/a/feeds/compliance/audit/account/example.com/abhishek/1234567' />
+  <apps:property name='requestId' value='1234567' />
+  <apps:property name='status' value='COMPLETED' />
+  <apps:property name='adminEmailAddress' value='admin@example.com' />
+  <apps:property name='userEmailAddress' value='abhishek@example.com' />
+  <apps:property name='requestDate' value='2009-09-10 09:16' />
+  <apps:property name='completedDate' value='2009-09-10 10:01' />
+  <apps:property name='numberOfFiles' value='1' />
+  <apps:property name='fileUrl0' value='https://apps-apis.google.com/a/data/compliance/audit/CQAAABW1_0' />
+</entry>`
+
+func TestAccountInfoFromXML(t *testing.T) {
+	a, err := accountInfoFromXML([]byte(accountInfoXML))
+	if err != nil {
+		t.Errorf("Expected nil but got %v", err)
+	}
+	_TestAccountInfo(a, t)
+}
+
+func _TestAccountInfo(a *AccountInfo, t *testing.T) {
+	for _, test := range []struct {
+		actual   interface{}
+		expected interface{}
+	}{
+		{a.RequestID, "1234567"},
+		{a.Status, CompletedStatus},
+		{a.DomainName, "example.com"},
+		{a.UserName, "abhishek"},
+		{a.AdminEmailAddress, "admin@example.com"},
+		{a.UserEmailAddress, "abhishek@example.com"},
+		{a.RequestDate.String(), time.Date(2009, time.September, 10, 9, 16, 0, 0, time.UTC).String()},
+		{a.CompletedDate.String(), time.Date(2009, time.September, 10, 10, 1, 0, 0, time.UTC).String()},
+		{a.NumberOfFiles, 1},
+		{len(a.FileURLs), 1},
+		{a.FileURLs[0], "https://apps-apis.google.com/a/data/compliance/audit/CQAAABW1_0"},
+		{a.Updated.UnixNano(), time.Date(2009, time.September, 10, 9, 16, 46, 213000000, time.UTC).UnixNano()},
+	} {
+		if test.actual != test.expected {
+			t.Errorf(`Expected "%v" but got "%v"`, test.expected, test.actual)
+		}
+	}
+}
+
+func TestAccountInfoFromXMLError(t *testing.T) {
+	a, err := accountInfoFromXML([]byte("<foo />"))
+	expected := "expected element type <entry> but have <foo>"
+	if err.Error() != expected {
+		t.Errorf(`Expected "%v" but got "%v"`, expected, err.Error())
+	}
+	if a != nil {
+		t.Errorf("Expected nil but got %v", a)
+	}
+}
+
+const accountInfosXML = `<?xml version="1.0" encoding="UTF-8"?>
+<feed xmlns="http://www.w3.org/2005/Atom" xmlns:apps="http://schemas.google.com/apps/2006">
+<id>https://apps-apis.google.com/a/feeds/compliance/audit/account/example.com</id>
+<updated>2009-09-10T09:16:46.213Z</updated>
+<entry>
+  <id>https://apps-apis.google.com/a/feeds/compliance/audit/account/example.com/abhishek/1234567</id>
+  <apps:property name="requestId" value="1234567"/>
+  <apps:property name="status" value="COMPLETED"/>
+</entry>
+<entry>
+  <id>https://apps-apis.google.com/a/feeds/compliance/audit/account/example.com/namrata/1234568</id>
+  <apps:property name="requestId" value="1234568"/>
+  <apps:property name="status" value="PENDING"/>
+</entry>
+</feed>
+`
@@ -0,0 +1,45 @@
+package emailaudit
+
+import "time"
+
+// Backoff Backoff
+type Backoff struct {
+	Initial    time.Duration
+	Max        time.Duration
+	Multiplier float64
+}
+
+// DefaultBackoff returns Backoff used when fields of Backoff are zero
+func DefaultBackoff() Backoff {
+	return Backoff{
+		Initial:    30 * time.Second,
+		Max:        15 * time.Minute,
+		Multiplier: 2,
+	}
+}
+
+func (b Backoff) withDefaults(d Backoff) Backoff {
+	if b.Initial <= 0 {
+		b.Initial = d.Initial
+	}
+	if b.Max <= 0 {
+		b.Max = d.Max
+	}
+	if b.Multiplier < 1 {
+		b.Multiplier = d.Multiplier
+	}
+	return b
+}
+
+// Duration returns the delay before the given retry attempt (0 origin)
+func (b Backoff) Duration(attempt int) time.Duration {
+	b = b.withDefaults(DefaultBackoff())
+	d := float64(b.Initial)
+	for i := 0; i < attempt; i++ {
+		d *= b.Multiplier
+		if d >= float64(b.Max) {
+			return b.Max
+		}
+	}
+	return time.Duration(d)
+}
@@ -0,0 +1,26 @@
+package emailaudit
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBackoffDuration(t *testing.T) {
+	b := Backoff{Initial: time.Second, Max: 5 * time.Second, Multiplier: 2}
+	for _, test := range []struct {
+		actual   interface{}
+		expected interface{}
+	}{
+		{b.Duration(0), time.Second},
+		{b.Duration(1), 2 * time.Second},
+		{b.Duration(2), 4 * time.Second},
+		{b.Duration(3), 5 * time.Second},
+		{b.Duration(30), 5 * time.Second},
+		{Backoff{}.Duration(0), 30 * time.Second},
+		{Backoff{}.Duration(10), 15 * time.Minute},
+	} {
+		if test.actual != test.expected {
+			t.Errorf(`Expected "%v" but got "%v"`, test.expected, test.actual)
+		}
+	}
+}
@@ -0,0 +1,81 @@
+package emailaudit
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+const defaultBulkConcurrency = 4
+
+// ErrBulkSkipped is the error of monitors not processed because a previous one failed with StopOnError
+var ErrBulkSkipped = errors.New("skipped after a previous error")
+
+// BulkOptions BulkOptions
+type BulkOptions struct {
+	// Concurrency is the number of monitors processed in parallel (default 4)
+	Concurrency int
+	// StopOnError skips the remaining monitors after the first failure
+	StopOnError bool
+}
+
+// BulkResult pairs an input monitor with its result
+type BulkResult struct {
+	Input   MailMonitor
+	Monitor *MailMonitor
+	Err     error
+}
+
+// UpdateMany creates or updates every monitor in parallel.
+// Each monitor needs DomainName, SourceUserName, DestUserName and EndDate.
+// The results are in the same order as monitors.
+// The returned error is the first failure with StopOnError, or the context error.
+func (svc *MailMonitorService) UpdateMany(ctx context.Context, monitors []MailMonitor, options BulkOptions) ([]BulkResult, error) {
+	return bulk(ctx, monitors, options, svc.update)
+}
+
+// DisableMany deletes every monitor in parallel.
+// Each monitor needs DomainName, SourceUserName and DestUserName.
+// The results are in the same order as monitors and have no Monitor.
+// The returned error is the first failure with StopOnError, or the context error.
+func (svc *MailMonitorService) DisableMany(ctx context.Context, monitors []MailMonitor, options BulkOptions) ([]BulkResult, error) {
+	return bulk(ctx, monitors, options, func(ctx context.Context, m MailMonitor) (*MailMonitor, error) {
+		return nil, svc.DisableContext(ctx, m.DomainName, m.SourceUserName, m.DestUserName)
+	})
+}
+
+func bulk(ctx context.Context, monitors []MailMonitor, options BulkOptions, fn func(ctx context.Context, m MailMonitor) (*MailMonitor, error)) ([]BulkResult, error) {
+	concurrency := options.Concurrency
+	if concurrency <= 0 {
+		concurrency = defaultBulkConcurrency
+	}
+	results := make([]BulkResult, len(monitors))
+	var mu sync.Mutex
+	var firstErr error
+	parallel(len(monitors), concurrency, func(i int) {
+		results[i].Input = monitors[i]
+		if err := ctx.Err(); err != nil {
+			results[i].Err = err
+			return
+		}
+		mu.Lock()
+		stopped := firstErr != nil
+		mu.Unlock()
+		if stopped {
+			results[i].Err = ErrBulkSkipped
+			return
+		}
+		results[i].Monitor, results[i].Err = fn(ctx, monitors[i])
+		if results[i].Err != nil && options.StopOnError {
+			mu.Lock()
+			if firstErr == nil {
+				firstErr = results[i].Err
+			}
+			mu.Unlock()
+		}
+	})
+	if firstErr == nil {
+		firstErr = ctx.Err()
+	}
+	return results, firstErr
+}
@@ -0,0 +1,147 @@
+package emailaudit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	gock "gopkg.in/h2non/gock.v1"
+)
+
+func bulkMonitors() []MailMonitor {
+	endDate := time.Now().Add(24 * time.Hour)
+	levels := MailMonitorLevels{IncomingEmail: FullMessageLevel}
+	return []MailMonitor{
+		NewMailMonitor("example.com", "abhishek", "namrata", endDate, levels),
+		NewMailMonitor("example.com", "bob", "namrata", endDate, levels),
+		NewMailMonitor("example.com", "carol", "namrata", endDate, levels),
+	}
+}
+
+func mockBulkUpdate() {
+	gock.New("https://apps-apis.google.com").
+		Post("/a/feeds/compliance/audit/mail/monitor/example.com/abhishek").
+		Reply(200).
+		XML(monitorXML)
+	gock.New("https://apps-apis.google.com").
+		Post("/a/feeds/compliance/audit/mail/monitor/example.com/bob").
+		Reply(400).
+		BodyString("Omg")
+	gock.New("https://apps-apis.google.com").
+		Post("/a/feeds/compliance/audit/mail/monitor/example.com/carol").
+		Reply(200).
+		XML(monitorXML)
+}
+
+func TestMailMonitorServiceUpdateMany(t *testing.T) {
+	defer gock.Off()
+	mockBulkUpdate()
+
+	monitors := bulkMonitors()
+	results, err := newTestService().MailMonitor.UpdateMany(context.Background(), monitors, BulkOptions{Concurrency: 2})
+	if err != nil {
+		t.Errorf("Expected nil but got %v", err)
+	}
+	if len(results) != 3 {
+		t.Fatalf("Expected 3 results but got %v", len(results))
+	}
+	for _, test := range []struct {
+		actual   interface{}
+		expected interface{}
+	}{
+		{results[0].Input.SourceUserName, "abhishek"},
+		{results[0].Err, nil},
+		{results[0].Monitor.DestUserName, "namrata"},
+		{results[1].Input.SourceUserName, "bob"},
+		{results[1].Err.Error(), "400 Bad Request: Omg"},
+		{results[1].Monitor == nil, true},
+		{results[2].Input.SourceUserName, "carol"},
+		{results[2].Err, nil},
+		{results[2].Monitor != nil, true},
+	} {
+		if test.actual != test.expected {
+			t.Errorf(`Expected "%v" but got "%v"`, test.expected, test.actual)
+		}
+	}
+}
+
+func TestMailMonitorServiceUpdateManyStopOnError(t *testing.T) {
+	defer gock.Off()
+	mockBulkUpdate()
+
+	results, err := newTestService().MailMonitor.UpdateMany(context.Background(), bulkMonitors(), BulkOptions{Concurrency: 1, StopOnError: true})
+	if err == nil || err.Error() != "400 Bad Request: Omg" {
+		t.Errorf(`Expected "400 Bad Request: Omg" but got "%v"`, err)
+	}
+	for _, test := range []struct {
+		actual   interface{}
+		expected interface{}
+	}{
+		{results[0].Err, nil},
+		{results[1].Err.Error(), "400 Bad Request: Omg"},
+		{results[2].Err, ErrBulkSkipped},
+		{results[2].Input.SourceUserName, "carol"},
+	} {
+		if test.actual != test.expected {
+			t.Errorf(`Expected "%v" but got "%v"`, test.expected, test.actual)
+		}
+	}
+}
+
+func TestMailMonitorServiceUpdateManyValidationError(t *testing.T) {
+	results, err := newTestService().MailMonitor.UpdateMany(context.Background(), []MailMonitor{{DomainName: "example.com", SourceUserName: "abhishek"}}, BulkOptions{})
+	if err != nil {
+		t.Errorf("Expected nil but got %v", err)
+	}
+	if _, ok := results[0].Err.(*ValidationError); !ok {
+		t.Errorf("Expected *ValidationError but got %T", results[0].Err)
+	}
+}
+
+func TestMailMonitorServiceDisableMany(t *testing.T) {
+	defer gock.Off()
+	gock.New("https://apps-apis.google.com").
+		Delete("/a/feeds/compliance/audit/mail/monitor/example.com/abhishek/namrata").
+		MatchHeader("Authorization", "Bearer test").
+		Reply(200)
+	gock.New("https://apps-apis.google.com").
+		Delete("/a/feeds/compliance/audit/mail/monitor/example.com/bob/namrata").
+		Reply(400).
+		BodyString("Omg")
+	gock.New("https://apps-apis.google.com").
+		Delete("/a/feeds/compliance/audit/mail/monitor/example.com/carol/namrata").
+		Reply(200)
+
+	results, err := newTestService().MailMonitor.DisableMany(context.Background(), bulkMonitors(), BulkOptions{})
+	if err != nil {
+		t.Errorf("Expected nil but got %v", err)
+	}
+	for _, test := range []struct {
+		actual   interface{}
+		expected interface{}
+	}{
+		{len(results), 3},
+		{results[0].Err, nil},
+		{results[0].Monitor == nil, true},
+		{results[1].Err.Error(), "400 Bad Request: Omg"},
+		{results[2].Err, nil},
+	} {
+		if test.actual != test.expected {
+			t.Errorf(`Expected "%v" but got "%v"`, test.expected, test.actual)
+		}
+	}
+}
+
+func TestMailMonitorServiceDisableManyContextCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	results, err := newTestService().MailMonitor.DisableMany(ctx, bulkMonitors(), BulkOptions{})
+	if err != context.Canceled {
+		t.Errorf(`Expected "%v" but got "%v"`, context.Canceled, err)
+	}
+	for _, r := range results {
+		if r.Err != context.Canceled {
+			t.Errorf(`Expected "%v" but got "%v"`, context.Canceled, r.Err)
+		}
+	}
+}
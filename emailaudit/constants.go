@@ -9,10 +9,47 @@ const (
 type MailMonitorLevel string
 
 const (
-	// NoneLevel HEADER_ONLY
+	// NoneLevel leaves the level unset, which is not sent on updates
 	NoneLevel MailMonitorLevel = ""
+	// ExplicitNoneLevel NONE, which is sent on updates to turn off monitoring of the level
+	ExplicitNoneLevel MailMonitorLevel = "NONE"
 	// HeaderOnlyLevel HEADER_ONLY
 	HeaderOnlyLevel MailMonitorLevel = "HEADER_ONLY"
 	// FullMessageLevel FULL_MESSAGE
 	FullMessageLevel MailMonitorLevel = "FULL_MESSAGE"
 )
+
+// MailboxExportPackageContent MailboxExportPackageContent
+type MailboxExportPackageContent string
+
+const (
+	// HeaderOnlyPackageContent HEADER_ONLY
+	HeaderOnlyPackageContent MailboxExportPackageContent = "HEADER_ONLY"
+	// FullMessagePackageContent FULL_MESSAGE
+	FullMessagePackageContent MailboxExportPackageContent = "FULL_MESSAGE"
+)
+
+// RequestStatus RequestStatus
+type RequestStatus string
+
+const (
+	// PendingStatus PENDING
+	PendingStatus RequestStatus = "PENDING"
+	// CompletedStatus COMPLETED
+	CompletedStatus RequestStatus = "COMPLETED"
+	// MarkedDeleteStatus MARKED_DELETE
+	MarkedDeleteStatus RequestStatus = "MARKED_DELETE"
+	// DeletedStatus DELETED
+	DeletedStatus RequestStatus = "DELETED"
+	// ExpiredStatus EXPIRED
+	ExpiredStatus RequestStatus = "EXPIRED"
+)
+
+// IsTerminal returns true if the request will not change its status anymore
+func (s RequestStatus) IsTerminal() bool {
+	switch s {
+	case CompletedStatus, MarkedDeleteStatus, DeletedStatus, ExpiredStatus:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,145 @@
+// Package convert writes messages of decrypted mailbox exports into
+// Maildir trees or directories of EML files.
+package convert
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"net/mail"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/ngs/go-google-email-audit-api/emailaudit/mbox"
+)
+
+// ManifestFileName is the name of the manifest written in the output directory
+const ManifestFileName = "manifest.json"
+
+const maxMessageIDLength = 100
+
+// Source is a decrypted mbox file of a mailbox export
+type Source struct {
+	RequestID string
+	FileIndex int
+	Reader    io.Reader
+}
+
+// Manifest Manifest
+type Manifest struct {
+	Entries []ManifestEntry `json:"entries"`
+}
+
+// ManifestEntry maps a written file back to the export file it came from
+type ManifestEntry struct {
+	File      string `json:"file"`
+	MessageID string `json:"messageId"`
+	RequestID string `json:"requestId"`
+	FileIndex int    `json:"fileIndex"`
+	Offset    int64  `json:"offset"`
+	SHA256    string `json:"sha256"`
+}
+
+// ToMaildir delivers every message of sources into the "new" folder of the Maildir at dir
+// and writes the manifest into dir
+func ToMaildir(dir string, sources ...Source) (*Manifest, error) {
+	for _, sub := range []string{"cur", "new", "tmp"} {
+		if err := os.MkdirAll(filepath.Join(dir, sub), 0700); err != nil {
+			return nil, err
+		}
+	}
+	return convert(dir, sources, func(name string, data []byte) (string, error) {
+		tmp := filepath.Join(dir, "tmp", name)
+		if err := ioutil.WriteFile(tmp, data, 0600); err != nil {
+			return "", err
+		}
+		file := filepath.Join("new", name)
+		return file, os.Rename(tmp, filepath.Join(dir, file))
+	})
+}
+
+// ToEML writes every message of sources as an .eml file into dir
+// and writes the manifest into dir
+func ToEML(dir string, sources ...Source) (*Manifest, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, err
+	}
+	return convert(dir, sources, func(name string, data []byte) (string, error) {
+		file := name + ".eml"
+		return file, ioutil.WriteFile(filepath.Join(dir, file), data, 0600)
+	})
+}
+
+func convert(dir string, sources []Source, write func(name string, data []byte) (string, error)) (*Manifest, error) {
+	manifest := &Manifest{Entries: []ManifestEntry{}}
+	for _, src := range sources {
+		r := mbox.NewReader(src.Reader)
+		for {
+			raw, err := r.NextRaw()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				return manifest, err
+			}
+			data, err := ioutil.ReadAll(raw)
+			if err != nil {
+				return manifest, err
+			}
+			sum := sha256.Sum256(data)
+			entry := ManifestEntry{
+				MessageID: messageID(data),
+				RequestID: src.RequestID,
+				FileIndex: src.FileIndex,
+				Offset:    r.Offset(),
+				SHA256:    hex.EncodeToString(sum[:]),
+			}
+			entry.File, err = write(FileName(entry.MessageID, entry.SHA256), data)
+			if err != nil {
+				return manifest, err
+			}
+			manifest.Entries = append(manifest.Entries, entry)
+		}
+	}
+	b, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return manifest, err
+	}
+	return manifest, ioutil.WriteFile(filepath.Join(dir, ManifestFileName), b, 0600)
+}
+
+// FileName returns the deterministic file name (without extension) of a message
+// made from its Message-ID and the hex encoded SHA-256 of its content
+func FileName(messageID string, hash string) string {
+	id := strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+			return r
+		case strings.ContainsRune("@.-_+=", r):
+			return r
+		}
+		return '_'
+	}, strings.Trim(messageID, "<> "))
+	if len(id) > maxMessageIDLength {
+		id = id[:maxMessageIDLength]
+	}
+	if id == "" {
+		id = "no-message-id"
+	}
+	if len(hash) > 16 {
+		hash = hash[:16]
+	}
+	return id + "-" + hash
+}
+
+func messageID(data []byte) string {
+	msg, err := mail.ReadMessage(bytes.NewReader(data))
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(msg.Header.Get("Message-ID"))
+}
@@ -0,0 +1,120 @@
+package convert
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const message1 = "Message-ID: <1@example.com>\nSubject: first\n\nHello\n"
+const message2 = "Subject: no id\n\nFrom here\n"
+const message3 = "Message-ID: <a/b:c@example.com>\nSubject: third\n\nBye\n"
+
+func sources() []Source {
+	return []Source{
+		{RequestID: "53156", FileIndex: 0, Reader: strings.NewReader(
+			"From a Thu Sep 10 09:16:46 2009\n" + message1 + "\n" +
+				"From b Thu Sep 10 09:16:46 2009\nSubject: no id\n\n>From here\n")},
+		{RequestID: "53156", FileIndex: 1, Reader: strings.NewReader(
+			"From c Thu Sep 10 09:16:46 2009\n" + message3)},
+	}
+}
+
+func hash(s string) string {
+	h := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(h[:])
+}
+
+func TestFileName(t *testing.T) {
+	for _, test := range []struct {
+		actual   interface{}
+		expected interface{}
+	}{
+		{FileName("<1@example.com>", "0123456789abcdef0123"), "1@example.com-0123456789abcdef"},
+		{FileName("<a/b:c d@example.com>", "0123"), "a_b_c_d@example.com-0123"},
+		{FileName("", "0123"), "no-message-id-0123"},
+		{len(FileName(strings.Repeat("x", 300), "0123")), 105},
+	} {
+		if test.actual != test.expected {
+			t.Errorf(`Expected "%v" but got "%v"`, test.expected, test.actual)
+		}
+	}
+}
+
+func _TestManifest(m *Manifest, t *testing.T) {
+	for _, test := range []struct {
+		actual   interface{}
+		expected interface{}
+	}{
+		{len(m.Entries), 3},
+		{m.Entries[0].MessageID, "<1@example.com>"},
+		{m.Entries[0].RequestID, "53156"},
+		{m.Entries[0].FileIndex, 0},
+		{m.Entries[0].Offset, int64(0)},
+		{m.Entries[0].SHA256, hash(message1)},
+		{m.Entries[1].MessageID, ""},
+		{m.Entries[1].SHA256, hash(message2)},
+		{m.Entries[1].Offset, int64(32 + len(message1) + 1)},
+		{m.Entries[2].FileIndex, 1},
+		{m.Entries[2].SHA256, hash(message3)},
+	} {
+		if test.actual != test.expected {
+			t.Errorf(`Expected "%v" but got "%v"`, test.expected, test.actual)
+		}
+	}
+}
+
+func TestToEML(t *testing.T) {
+	dir, _ := ioutil.TempDir("", "convert")
+	defer os.RemoveAll(dir)
+	m, err := ToEML(dir, sources()...)
+	if err != nil {
+		t.Fatalf("Expected nil but got %v", err)
+	}
+	_TestManifest(m, t)
+	expected := "1@example.com-" + hash(message1)[:16] + ".eml"
+	if m.Entries[0].File != expected {
+		t.Errorf(`Expected "%v" but got "%v"`, expected, m.Entries[0].File)
+	}
+	for i, content := range []string{message1, message2, message3} {
+		b, _ := ioutil.ReadFile(filepath.Join(dir, m.Entries[i].File))
+		if string(b) != content {
+			t.Errorf(`Expected "%v" but got "%v"`, content, string(b))
+		}
+	}
+	var written Manifest
+	b, _ := ioutil.ReadFile(filepath.Join(dir, ManifestFileName))
+	if err := json.Unmarshal(b, &written); err != nil {
+		t.Errorf("Expected nil but got %v", err)
+	}
+	_TestManifest(&written, t)
+}
+
+func TestToMaildir(t *testing.T) {
+	dir, _ := ioutil.TempDir("", "convert")
+	defer os.RemoveAll(dir)
+	m, err := ToMaildir(dir, sources()...)
+	if err != nil {
+		t.Fatalf("Expected nil but got %v", err)
+	}
+	_TestManifest(m, t)
+	expected := filepath.Join("new", "no-message-id-"+hash(message2)[:16])
+	if m.Entries[1].File != expected {
+		t.Errorf(`Expected "%v" but got "%v"`, expected, m.Entries[1].File)
+	}
+	b, _ := ioutil.ReadFile(filepath.Join(dir, m.Entries[2].File))
+	if string(b) != message3 {
+		t.Errorf(`Expected "%v" but got "%v"`, message3, string(b))
+	}
+	for _, sub := range []string{"cur", "tmp"} {
+		files, _ := ioutil.ReadDir(filepath.Join(dir, sub))
+		if len(files) != 0 {
+			t.Errorf("Expected 0 but got %v", len(files))
+		}
+	}
+}
@@ -0,0 +1,105 @@
+package emailaudit
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"unicode/utf8"
+)
+
+// Sentinel errors matching APIError by error code with errors.Is
+// - https://developers.google.com/admin-sdk/email-audit/#error_codes
+var (
+	ErrUnknown                    = &APIError{ErrorCode: "1000", Reason: "UnknownError"}
+	ErrUserDeletedRecently        = &APIError{ErrorCode: "1100", Reason: "UserDeletedRecently"}
+	ErrUserSuspended              = &APIError{ErrorCode: "1101", Reason: "UserSuspended"}
+	ErrDomainSuspended            = &APIError{ErrorCode: "1202", Reason: "DomainSuspended"}
+	ErrDomainFeatureUnavailable   = &APIError{ErrorCode: "1203", Reason: "DomainFeatureUnavailable"}
+	ErrEntityExists               = &APIError{ErrorCode: "1300", Reason: "EntityExists"}
+	ErrEntityDoesNotExist         = &APIError{ErrorCode: "1301", Reason: "EntityDoesNotExist"}
+	ErrEntityNameNotValid         = &APIError{ErrorCode: "1303", Reason: "EntityNameNotValid"}
+	ErrInvalidEmailAddress        = &APIError{ErrorCode: "1406", Reason: "InvalidEmailAddress"}
+	ErrInvalidQueryParameterValue = &APIError{ErrorCode: "1407", Reason: "InvalidQueryParameterValue"}
+)
+
+// APIError is returned for non-2xx responses.
+// ErrorCode, Reason and InvalidInput are parsed from AppsForYourDomainErrors bodies.
+type APIError struct {
+	StatusCode   int
+	ErrorCode    string
+	Reason       string
+	InvalidInput string
+	Body         string
+}
+
+func (e *APIError) Error() string {
+	var message string
+	switch {
+	case e.ErrorCode != "" || e.Reason != "":
+		message = fmt.Sprintf("%v (errorCode %v)", e.Reason, e.ErrorCode)
+		if e.InvalidInput != "" {
+			message += ": " + e.InvalidInput
+		}
+	default:
+		message = truncateErrorBody(e.Body)
+	}
+	if e.StatusCode == 0 {
+		return message
+	}
+	status := fmt.Sprintf("%d %v", e.StatusCode, http.StatusText(e.StatusCode))
+	if message == "" {
+		return status
+	}
+	return status + ": " + message
+}
+
+const maxErrorBodyLength = 200
+
+// truncateErrorBody shortens body, such as an HTML error page, to maxErrorBodyLength bytes
+func truncateErrorBody(body string) string {
+	body = strings.TrimSpace(body)
+	if len(body) <= maxErrorBodyLength {
+		return body
+	}
+	i := maxErrorBodyLength
+	for i > 0 && !utf8.RuneStart(body[i]) {
+		i--
+	}
+	return body[:i] + "..."
+}
+
+// Is reports whether target is an APIError with the same error code.
+// 404 responses without error code match ErrEntityDoesNotExist.
+func (e *APIError) Is(target error) bool {
+	t, ok := target.(*APIError)
+	if !ok {
+		return false
+	}
+	if e.ErrorCode == "" && e.StatusCode == http.StatusNotFound && t == ErrEntityDoesNotExist {
+		return true
+	}
+	return t.ErrorCode != "" && t.ErrorCode == e.ErrorCode
+}
+
+type appsForYourDomainErrors struct {
+	XMLName xml.Name `xml:"AppsForYourDomainErrors"`
+	Errors  []struct {
+		ErrorCode    string `xml:"errorCode,attr"`
+		Reason       string `xml:"reason,attr"`
+		InvalidInput string `xml:"invalidInput,attr"`
+	} `xml:"error"`
+}
+
+func responseError(res *http.Response) error {
+	bytes, _ := ioutil.ReadAll(res.Body)
+	e := &APIError{StatusCode: res.StatusCode, Body: string(bytes)}
+	var v appsForYourDomainErrors
+	if err := xml.Unmarshal(bytes, &v); err == nil && len(v.Errors) > 0 {
+		e.ErrorCode = v.Errors[0].ErrorCode
+		e.Reason = v.Errors[0].Reason
+		e.InvalidInput = v.Errors[0].InvalidInput
+	}
+	return e
+}
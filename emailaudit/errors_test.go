@@ -0,0 +1,82 @@
+package emailaudit
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	gock "gopkg.in/h2non/gock.v1"
+)
+
+const entityDoesNotExistXML = `<?xml version="1.0" encoding="UTF-8"?>
+<AppsForYourDomainErrors>
+  <error errorCode="1301" invalidInput="namrata" reason="EntityDoesNotExist" />
+</AppsForYourDomainErrors>`
+
+func TestAPIError(t *testing.T) {
+	for _, test := range []struct {
+		err      *APIError
+		target   error
+		is       bool
+		expected string
+	}{
+		{&APIError{StatusCode: 400, ErrorCode: "1301", Reason: "EntityDoesNotExist", InvalidInput: "namrata"}, ErrEntityDoesNotExist, true, "400 Bad Request: EntityDoesNotExist (errorCode 1301): namrata"},
+		{&APIError{StatusCode: 400, ErrorCode: "1300", Reason: "EntityExists"}, ErrEntityDoesNotExist, false, "400 Bad Request: EntityExists (errorCode 1300)"},
+		{&APIError{StatusCode: 400, ErrorCode: "1300", Reason: "EntityExists"}, ErrEntityExists, true, "400 Bad Request: EntityExists (errorCode 1300)"},
+		{&APIError{StatusCode: 404, Body: "Not Found"}, ErrEntityDoesNotExist, true, "404 Not Found: Not Found"},
+		{&APIError{StatusCode: 404, Body: "Not Found"}, ErrEntityExists, false, "404 Not Found: Not Found"},
+		{&APIError{StatusCode: 503, Body: "Omg"}, ErrUnknown, false, "503 Service Unavailable: Omg"},
+		{&APIError{StatusCode: 502}, ErrUnknown, false, "502 Bad Gateway"},
+		{&APIError{StatusCode: 500, Body: "\n<html>" + strings.Repeat("x", 300) + "</html>\n"}, ErrUnknown, false, "500 Internal Server Error: <html>" + strings.Repeat("x", 194) + "..."},
+		{&APIError{StatusCode: 400, ErrorCode: "1301"}, errors.New("1301"), false, "400 Bad Request:  (errorCode 1301)"},
+		{ErrEntityDoesNotExist, ErrEntityDoesNotExist, true, "EntityDoesNotExist (errorCode 1301)"},
+	} {
+		if actual := errors.Is(test.err, test.target); actual != test.is {
+			t.Errorf(`Expected "%v" but got "%v" for %v`, test.is, actual, test.err)
+		}
+		if actual := test.err.Error(); actual != test.expected {
+			t.Errorf(`Expected "%v" but got "%v"`, test.expected, actual)
+		}
+	}
+}
+
+func TestMailMonitorServiceAPIError(t *testing.T) {
+	defer gock.Off()
+	gock.New("https://apps-apis.google.com").
+		Persist().
+		Reply(400).
+		BodyString(entityDoesNotExistXML)
+
+	svc := newTestService()
+	endDate := time.Date(2116, time.October, 30, 14, 59, 0, 0, time.UTC)
+	_, err1 := svc.MailMonitor.Update("example.com", "abhishek", "namrata", endDate, MailMonitorLevels{Chat: HeaderOnlyLevel})
+	_, err2 := svc.MailMonitor.List("example.com", "abhishek")
+	err3 := svc.MailMonitor.Disable("example.com", "abhishek", "namrata")
+	_, err4 := svc.MailMonitor.Get("example.com", "abhishek", "namrata")
+	for _, err := range []error{err1, err2, err3, err4} {
+		if !errors.Is(err, ErrEntityDoesNotExist) {
+			t.Errorf(`Expected "%v" but got "%v"`, ErrEntityDoesNotExist, err)
+		}
+		var apiErr *APIError
+		if !errors.As(err, &apiErr) {
+			t.Errorf("Expected *APIError but got %T", err)
+			continue
+		}
+		for _, test := range []struct {
+			actual   interface{}
+			expected interface{}
+		}{
+			{apiErr.StatusCode, http.StatusBadRequest},
+			{apiErr.ErrorCode, "1301"},
+			{apiErr.Reason, "EntityDoesNotExist"},
+			{apiErr.InvalidInput, "namrata"},
+			{apiErr.Body, entityDoesNotExistXML},
+		} {
+			if test.actual != test.expected {
+				t.Errorf(`Expected "%v" but got "%v"`, test.expected, test.actual)
+			}
+		}
+	}
+}
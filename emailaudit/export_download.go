@@ -0,0 +1,183 @@
+package emailaudit
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+const defaultDownloadConcurrency = 4
+
+// DownloadOptions DownloadOptions
+type DownloadOptions struct {
+	// Concurrency is the number of files downloaded in parallel (default 4)
+	Concurrency int
+}
+
+// DownloadedFile DownloadedFile
+type DownloadedFile struct {
+	Index   int
+	URL     string
+	Path    string
+	Size    int64
+	SHA256  string
+	Resumed bool
+}
+
+// FileName returns the name of the index-th file of the export used by DownloadToDir
+func (req *MailboxExport) FileName(index int) string {
+	return fmt.Sprintf("%v-%v-%v.mbox.gpg", req.UserName, req.RequestID, index)
+}
+
+// Download streams every encrypted file of a completed mailbox export
+// to the writer returned by newWriter for its index
+func (svc *MailboxExportService) Download(ctx context.Context, export *MailboxExport, newWriter func(index int) (io.Writer, error), options DownloadOptions) ([]DownloadedFile, error) {
+	return svc.download(ctx, export, options, func(ctx context.Context, index int) (DownloadedFile, error) {
+		file := DownloadedFile{Index: index, URL: export.FileURLs[index]}
+		w, err := newWriter(index)
+		if err != nil {
+			return file, err
+		}
+		h := sha256.New()
+		res, err := svc.s.stream(ctx, export.DomainName, file.URL, nil)
+		if err != nil {
+			return file, err
+		}
+		defer res.Body.Close()
+		if !(res.StatusCode >= 200 && res.StatusCode < 300) {
+			return file, responseError(res)
+		}
+		file.Size, err = io.Copy(io.MultiWriter(w, h), res.Body)
+		file.SHA256 = hex.EncodeToString(h.Sum(nil))
+		return file, err
+	})
+}
+
+// DownloadToDir downloads every encrypted file of a completed mailbox export into dir.
+// Partially downloaded files are resumed with HTTP Range requests.
+func (svc *MailboxExportService) DownloadToDir(ctx context.Context, export *MailboxExport, dir string, options DownloadOptions) ([]DownloadedFile, error) {
+	return svc.download(ctx, export, options, func(ctx context.Context, index int) (DownloadedFile, error) {
+		file := DownloadedFile{
+			Index: index,
+			URL:   export.FileURLs[index],
+			Path:  filepath.Join(dir, export.FileName(index)),
+		}
+		f, err := os.OpenFile(file.Path, os.O_RDWR|os.O_CREATE, 0600)
+		if err != nil {
+			return file, err
+		}
+		defer f.Close()
+		h := sha256.New()
+		offset, err := io.Copy(h, f)
+		if err != nil {
+			return file, err
+		}
+		file.Size, file.Resumed, err = svc.resume(ctx, export.DomainName, file.URL, f, h, offset)
+		file.SHA256 = hex.EncodeToString(h.Sum(nil))
+		return file, err
+	})
+}
+
+func (svc *MailboxExportService) resume(ctx context.Context, domainName string, url string, f *os.File, h hash.Hash, offset int64) (int64, bool, error) {
+	header := http.Header{}
+	if offset > 0 {
+		header.Set("Range", fmt.Sprintf("bytes=%d-", offset))
+	}
+	res, err := svc.s.stream(ctx, domainName, url, header)
+	if err != nil {
+		return offset, false, err
+	}
+	defer res.Body.Close()
+	resumed := false
+	switch {
+	case res.StatusCode == http.StatusPartialContent && offset > 0:
+		resumed = true
+	case res.StatusCode == http.StatusRequestedRangeNotSatisfiable && offset > 0:
+		if size, ok := unsatisfiedRangeSize(res); ok && size == offset {
+			// already downloaded entirely
+			return offset, true, nil
+		}
+		// the local file is longer than or differs from the remote one, start over
+		if err := restartFile(f, h); err != nil {
+			return 0, false, err
+		}
+		return svc.resume(ctx, domainName, url, f, h, 0)
+	case res.StatusCode >= 200 && res.StatusCode < 300:
+		// the server ignored Range, start over
+		if err := restartFile(f, h); err != nil {
+			return 0, false, err
+		}
+		offset = 0
+	default:
+		return offset, false, responseError(res)
+	}
+	n, err := io.Copy(io.MultiWriter(f, h), res.Body)
+	return offset + n, resumed, err
+}
+
+func restartFile(f *os.File, h hash.Hash) error {
+	if err := f.Truncate(0); err != nil {
+		return err
+	}
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	h.Reset()
+	return nil
+}
+
+// unsatisfiedRangeSize returns the remote size in Content-Range "bytes */<size>" of 416 responses
+func unsatisfiedRangeSize(res *http.Response) (int64, bool) {
+	value := res.Header.Get("Content-Range")
+	if !strings.HasPrefix(value, "bytes */") {
+		return 0, false
+	}
+	size, err := strconv.ParseInt(strings.TrimPrefix(value, "bytes */"), 10, 64)
+	if err != nil {
+		return 0, false
+	}
+	return size, true
+}
+
+func (svc *MailboxExportService) download(ctx context.Context, export *MailboxExport, options DownloadOptions, fetch func(ctx context.Context, index int) (DownloadedFile, error)) ([]DownloadedFile, error) {
+	if export == nil || len(export.FileURLs) == 0 {
+		return nil, errors.New("export has no files to download")
+	}
+	concurrency := options.Concurrency
+	if concurrency <= 0 {
+		concurrency = defaultDownloadConcurrency
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	files := make([]DownloadedFile, len(export.FileURLs))
+	var once sync.Once
+	var firstErr error
+	parallel(len(files), concurrency, func(i int) {
+		if ctx.Err() != nil {
+			files[i] = DownloadedFile{Index: i, URL: export.FileURLs[i]}
+			return
+		}
+		file, err := fetch(ctx, i)
+		files[i] = file
+		if err != nil {
+			once.Do(func() {
+				firstErr = fmt.Errorf("file %d: %w", i, err)
+				cancel()
+			})
+		}
+	})
+	if firstErr == nil && ctx.Err() != nil {
+		firstErr = ctx.Err()
+	}
+	return files, firstErr
+}
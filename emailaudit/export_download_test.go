@@ -0,0 +1,212 @@
+package emailaudit
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	gock "gopkg.in/h2non/gock.v1"
+)
+
+func completedExport() *MailboxExport {
+	e, _ := exportFromXML([]byte(completedExportXML))
+	e.UserName = "abhishek"
+	return e
+}
+
+func sha256Hex(s string) string {
+	h := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(h[:])
+}
+
+func TestMailboxExportServiceDownload(t *testing.T) {
+	defer gock.Off()
+	gock.New("https://apps-apis.google.com").
+		Get("/a/data/compliance/audit/OQAAABW1_0").
+		MatchHeader("Authorization", "Bearer test").
+		MatchHeader("User-Agent", "google-api-go-client/0.5").
+		Reply(200).
+		BodyString("file0")
+	gock.New("https://apps-apis.google.com").
+		Get("/a/data/compliance/audit/OQAAABW1_1").
+		MatchHeader("Authorization", "Bearer test").
+		Reply(200).
+		BodyString("file1")
+
+	buffers := []*bytes.Buffer{{}, {}}
+	files, err := newTestService().Export.Download(context.Background(), completedExport(), func(i int) (io.Writer, error) {
+		return buffers[i], nil
+	}, DownloadOptions{Concurrency: 2})
+	if err != nil {
+		t.Errorf("Expected nil but got %v", err)
+	}
+	for _, test := range []struct {
+		actual   interface{}
+		expected interface{}
+	}{
+		{len(files), 2},
+		{buffers[0].String(), "file0"},
+		{buffers[1].String(), "file1"},
+		{files[0].Index, 0},
+		{files[0].Size, int64(5)},
+		{files[0].SHA256, sha256Hex("file0")},
+		{files[0].URL, "https://apps-apis.google.com/a/data/compliance/audit/OQAAABW1_0"},
+		{files[1].SHA256, sha256Hex("file1")},
+		{files[1].Resumed, false},
+	} {
+		if test.actual != test.expected {
+			t.Errorf(`Expected "%v" but got "%v"`, test.expected, test.actual)
+		}
+	}
+}
+
+func TestMailboxExportServiceDownloadToDir(t *testing.T) {
+	defer gock.Off()
+	dir, _ := ioutil.TempDir("", "emailaudit")
+	defer os.RemoveAll(dir)
+	export := completedExport()
+	ioutil.WriteFile(filepath.Join(dir, export.FileName(0)), []byte("fi"), 0600)
+	ioutil.WriteFile(filepath.Join(dir, export.FileName(1)), []byte("file1"), 0600)
+
+	gock.New("https://apps-apis.google.com").
+		Get("/a/data/compliance/audit/OQAAABW1_0").
+		MatchHeader("Range", "bytes=2-").
+		Reply(206).
+		BodyString("le0")
+	gock.New("https://apps-apis.google.com").
+		Get("/a/data/compliance/audit/OQAAABW1_1").
+		MatchHeader("Range", "bytes=5-").
+		Reply(416).
+		SetHeader("Content-Range", "bytes */5")
+
+	files, err := newTestService().Export.DownloadToDir(context.Background(), export, dir, DownloadOptions{})
+	if err != nil {
+		t.Errorf("Expected nil but got %v", err)
+	}
+	content, _ := ioutil.ReadFile(filepath.Join(dir, "abhishek-53156-0.mbox.gpg"))
+	for _, test := range []struct {
+		actual   interface{}
+		expected interface{}
+	}{
+		{len(files), 2},
+		{string(content), "file0"},
+		{files[0].Path, filepath.Join(dir, "abhishek-53156-0.mbox.gpg")},
+		{files[0].Size, int64(5)},
+		{files[0].SHA256, sha256Hex("file0")},
+		{files[0].Resumed, true},
+		{files[1].Size, int64(5)},
+		{files[1].SHA256, sha256Hex("file1")},
+	} {
+		if test.actual != test.expected {
+			t.Errorf(`Expected "%v" but got "%v"`, test.expected, test.actual)
+		}
+	}
+}
+
+func TestMailboxExportServiceDownloadToDirRangeIgnored(t *testing.T) {
+	defer gock.Off()
+	dir, _ := ioutil.TempDir("", "emailaudit")
+	defer os.RemoveAll(dir)
+	export := completedExport()
+	export.FileURLs = export.FileURLs[:1]
+	ioutil.WriteFile(filepath.Join(dir, export.FileName(0)), []byte("xx"), 0600)
+
+	gock.New("https://apps-apis.google.com").
+		Get("/a/data/compliance/audit/OQAAABW1_0").
+		Reply(200).
+		BodyString("file0")
+
+	files, err := newTestService().Export.DownloadToDir(context.Background(), export, dir, DownloadOptions{})
+	if err != nil {
+		t.Errorf("Expected nil but got %v", err)
+	}
+	content, _ := ioutil.ReadFile(files[0].Path)
+	for _, test := range []struct {
+		actual   interface{}
+		expected interface{}
+	}{
+		{string(content), "file0"},
+		{files[0].SHA256, sha256Hex("file0")},
+		{files[0].Resumed, false},
+	} {
+		if test.actual != test.expected {
+			t.Errorf(`Expected "%v" but got "%v"`, test.expected, test.actual)
+		}
+	}
+}
+
+func TestMailboxExportServiceDownloadToDirLocalFileLonger(t *testing.T) {
+	defer gock.Off()
+	dir, _ := ioutil.TempDir("", "emailaudit")
+	defer os.RemoveAll(dir)
+	export := completedExport()
+	export.FileURLs = export.FileURLs[:1]
+	ioutil.WriteFile(filepath.Join(dir, export.FileName(0)), []byte("file0xx"), 0600)
+
+	gock.New("https://apps-apis.google.com").
+		Get("/a/data/compliance/audit/OQAAABW1_0").
+		MatchHeader("Range", "bytes=7-").
+		Reply(416).
+		SetHeader("Content-Range", "bytes */5")
+	gock.New("https://apps-apis.google.com").
+		Get("/a/data/compliance/audit/OQAAABW1_0").
+		Reply(200).
+		BodyString("file0")
+
+	files, err := newTestService().Export.DownloadToDir(context.Background(), export, dir, DownloadOptions{})
+	if err != nil {
+		t.Errorf("Expected nil but got %v", err)
+	}
+	content, _ := ioutil.ReadFile(files[0].Path)
+	for _, test := range []struct {
+		actual   interface{}
+		expected interface{}
+	}{
+		{string(content), "file0"},
+		{files[0].Size, int64(5)},
+		{files[0].SHA256, sha256Hex("file0")},
+		{files[0].Resumed, false},
+		{gock.IsDone(), true},
+	} {
+		if test.actual != test.expected {
+			t.Errorf(`Expected "%v" but got "%v"`, test.expected, test.actual)
+		}
+	}
+}
+
+func TestMailboxExportServiceDownloadHTTPErrorResponse(t *testing.T) {
+	defer gock.Off()
+	gock.New("https://apps-apis.google.com").
+		Get("/a/data/compliance/audit/OQAAABW1_0").
+		Reply(403).
+		BodyString("Omg")
+
+	export := completedExport()
+	export.FileURLs = export.FileURLs[:1]
+	_, err := newTestService().Export.Download(context.Background(), export, func(i int) (io.Writer, error) {
+		return ioutil.Discard, nil
+	}, DownloadOptions{})
+	expected := "file 0: 403 Forbidden: Omg"
+	if err == nil || err.Error() != expected {
+		t.Errorf(`Expected "%v" but got "%v"`, expected, err)
+	}
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != 403 {
+		t.Errorf("Expected *APIError of 403 but got %v", err)
+	}
+}
+
+func TestMailboxExportServiceDownloadNoFiles(t *testing.T) {
+	_, err := newTestService().Export.DownloadToDir(context.Background(), &MailboxExport{}, "", DownloadOptions{})
+	expected := "export has no files to download"
+	if err == nil || err.Error() != expected {
+		t.Errorf(`Expected "%v" but got "%v"`, expected, err)
+	}
+}
@@ -0,0 +1,173 @@
+package emailaudit
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// MailboxExportService MailboxExportService
+type MailboxExportService struct {
+	s *Service
+}
+
+// WaitOptions WaitOptions
+type WaitOptions struct {
+	Backoff  Backoff
+	Progress func(export *MailboxExport)
+}
+
+// NewMailboxExportService returns new MailboxExportService
+func NewMailboxExportService(s *Service) *MailboxExportService {
+	rs := &MailboxExportService{s: s}
+	return rs
+}
+
+// Create creates a mailbox export request
+// - https://developers.google.com/admin-sdk/email-audit/#creating_a_mailbox_export_request
+func (svc *MailboxExportService) Create(domainName string, userName string, options MailboxExportOptions) (*MailboxExport, error) {
+	return svc.CreateContext(context.Background(), domainName, userName, options)
+}
+
+// CreateContext is Create with a context
+func (svc *MailboxExportService) CreateContext(ctx context.Context, domainName string, userName string, options MailboxExportOptions) (*MailboxExport, error) {
+	if err := validateQuery(options.SearchQuery); err != nil {
+		return nil, err
+	}
+	export := NewMailboxExport(domainName, userName, options)
+	export.baseURL = svc.s.baseURL
+	bytes, err := svc.s.do(ctx, domainName, "POST", export.URL(), export.toXML())
+	if err != nil {
+		return nil, err
+	}
+	return svc.exportFromXML(bytes)
+}
+
+// Get retrieves a mailbox export request status
+// - https://developers.google.com/admin-sdk/email-audit/#retrieving_a_mailbox_export_request_status
+func (svc *MailboxExportService) Get(domainName string, userName string, requestID string) (*MailboxExport, error) {
+	return svc.GetContext(context.Background(), domainName, userName, requestID)
+}
+
+// GetContext is Get with a context
+func (svc *MailboxExportService) GetContext(ctx context.Context, domainName string, userName string, requestID string) (*MailboxExport, error) {
+	url := fmt.Sprintf("%v/%v/%v/%v", svc.s.endpoint(exportPath), domainName, userName, requestID)
+	bytes, err := svc.s.do(ctx, domainName, "GET", url, nil)
+	if err != nil {
+		return nil, err
+	}
+	return svc.exportFromXML(bytes)
+}
+
+// ListAll retrieves all mailbox export requests in the domain.
+// Requests created before fromDate are omitted unless fromDate is nil.
+// - https://developers.google.com/admin-sdk/email-audit/#retrieving_all_mailbox_export_requests_in_a_domain
+func (svc *MailboxExportService) ListAll(domainName string, fromDate *time.Time) ([]MailboxExport, error) {
+	return svc.ListAllContext(context.Background(), domainName, fromDate)
+}
+
+// ListAllContext is ListAll with a context
+func (svc *MailboxExportService) ListAllContext(ctx context.Context, domainName string, fromDate *time.Time) ([]MailboxExport, error) {
+	url := fmt.Sprintf("%v/%v", svc.s.endpoint(exportPath), domainName)
+	if fromDate != nil {
+		url += "?" + fromDateQuery(fromDate)
+	}
+	entries, err := svc.s.getFeed(ctx, domainName, url)
+	if err != nil {
+		return nil, err
+	}
+	var exports []MailboxExport
+	for _, v := range entries {
+		e := v.toMailboxExport()
+		e.baseURL = svc.s.baseURL
+		exports = append(exports, e)
+	}
+	return exports, nil
+}
+
+// Delete deletes a mailbox export request and its encrypted files
+// - https://developers.google.com/admin-sdk/email-audit/#deleting_a_mailbox_export_request
+func (svc *MailboxExportService) Delete(domainName string, userName string, requestID string) error {
+	return svc.DeleteContext(context.Background(), domainName, userName, requestID)
+}
+
+// DeleteContext is Delete with a context
+func (svc *MailboxExportService) DeleteContext(ctx context.Context, domainName string, userName string, requestID string) error {
+	url := fmt.Sprintf("%v/%v/%v/%v", svc.s.endpoint(exportPath), domainName, userName, requestID)
+	_, err := svc.s.do(ctx, domainName, "DELETE", url, nil)
+	return err
+}
+
+func (svc *MailboxExportService) exportFromXML(data []byte) (*MailboxExport, error) {
+	e, err := exportFromXML(data)
+	if e != nil {
+		e.baseURL = svc.s.baseURL
+	}
+	return e, err
+}
+
+// PurgeResult PurgeResult
+type PurgeResult struct {
+	Export MailboxExport
+	Err    error
+}
+
+// Purge deletes every mailbox export request in the domain completed more than olderThan ago.
+// Requests already deleted or marked for deletion are left as they are.
+// A failure to delete one request is reported in its PurgeResult and does not stop the others.
+func (svc *MailboxExportService) Purge(ctx context.Context, domainName string, olderThan time.Duration) ([]PurgeResult, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	exports, err := svc.ListAllContext(ctx, domainName, nil)
+	if err != nil {
+		return nil, err
+	}
+	threshold := svc.s.clock.Now().Add(-olderThan)
+	var results []PurgeResult
+	for _, e := range exports {
+		if e.CompletedDate == nil || !e.CompletedDate.Before(threshold) {
+			continue
+		}
+		if e.Status == DeletedStatus || e.Status == MarkedDeleteStatus {
+			continue
+		}
+		if err := ctx.Err(); err != nil {
+			return results, err
+		}
+		err := svc.DeleteContext(ctx, domainName, e.UserName, e.RequestID)
+		results = append(results, PurgeResult{Export: e, Err: err})
+	}
+	return results, nil
+}
+
+// WaitForCompletion polls a mailbox export request until its status becomes terminal
+// (COMPLETED, MARKED_DELETE, DELETED or EXPIRED) and returns the last retrieved export.
+// Network errors, rate limit errors and 429, 500, 502, 503 and 504 responses are retried
+// with the wait backoff until ctx is done; other errors end the wait.
+func (svc *MailboxExportService) WaitForCompletion(ctx context.Context, domainName string, userName string, requestID string, options WaitOptions) (*MailboxExport, error) {
+	var last *MailboxExport
+	for attempt := 0; ; attempt++ {
+		export, err := svc.GetContext(ctx, domainName, userName, requestID)
+		if err != nil {
+			if ctx.Err() != nil || !isRetryableError(err) {
+				return last, err
+			}
+			svc.s.logf("emailaudit: waiting for export %v failed: %v, retrying", requestID, err)
+			if err := svc.s.sleep(ctx, options.Backoff.Duration(attempt)); err != nil {
+				return last, err
+			}
+			continue
+		}
+		last = export
+		if options.Progress != nil {
+			options.Progress(export)
+		}
+		if export.Status.IsTerminal() {
+			return export, nil
+		}
+		if err := svc.s.sleep(ctx, options.Backoff.Duration(attempt)); err != nil {
+			return export, err
+		}
+	}
+}
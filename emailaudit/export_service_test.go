@@ -0,0 +1,428 @@
+package emailaudit
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/oauth2"
+
+	gock "gopkg.in/h2non/gock.v1"
+)
+
+func newTestService(opts ...Option) *Service {
+	ctx := context.Background()
+	config := &oauth2.Config{}
+	token := &oauth2.Token{AccessToken: "test"}
+	client := config.Client(ctx, token)
+	svc, _ := New(client, opts...)
+	return svc
+}
+
+func TestMailboxExportServiceCreate(t *testing.T) {
+	defer gock.Off()
+	gock.New("https://apps-apis.google.com").
+		Post("/a/feeds/compliance/audit/mail/export/example.com/abhishek").
+		MatchType("application/atom\\+xml").
+		MatchHeader("Authorization", "Bearer test").
+		MatchHeader("User-Agent", "google-api-go-client/0.5").
+		Reply(201).
+		XML(exportXML)
+
+	e, err := newTestService().Export.Create("example.com", "abhishek", MailboxExportOptions{
+		SearchQuery:    "in:chats",
+		IncludeDeleted: true,
+	})
+	if err != nil {
+		t.Errorf("Expected nil but got %v", err)
+	}
+	_TestExport(e, t)
+}
+
+func TestMailboxExportServiceCreateHTTPErrorResponse(t *testing.T) {
+	defer gock.Off()
+	gock.New("https://apps-apis.google.com").
+		Post("/a/feeds/compliance/audit/mail/export/example.com/abhishek").
+		Reply(400).
+		BodyString("Omg")
+
+	e, err := newTestService().Export.Create("example.com", "abhishek", MailboxExportOptions{})
+	expected := "400 Bad Request: Omg"
+	if err == nil || err.Error() != expected {
+		t.Errorf(`Expected "%v" but got "%v"`, expected, err)
+	}
+	if e != nil {
+		t.Errorf("Expected nil but got %v", e)
+	}
+}
+
+func TestMailboxExportServiceGet(t *testing.T) {
+	defer gock.Off()
+	gock.New("https://apps-apis.google.com").
+		Get("/a/feeds/compliance/audit/mail/export/example.com/abhishek/53156").
+		MatchHeader("Authorization", "Bearer test").
+		MatchHeader("User-Agent", "google-api-go-client/0.5").
+		Reply(200).
+		XML(exportXML)
+
+	e, err := newTestService().Export.Get("example.com", "abhishek", "53156")
+	if err != nil {
+		t.Errorf("Expected nil but got %v", err)
+	}
+	_TestExport(e, t)
+}
+
+func TestMailboxExportServiceBaseURL(t *testing.T) {
+	defer gock.Off()
+	gock.New("https://proxy.example.com").
+		Get("/audit/mail/export/example.com/abhishek/53156").
+		MatchHeader("Authorization", "Bearer test").
+		Reply(200).
+		XML(exportXML)
+
+	svc := newTestService(WithBaseURL("https://proxy.example.com/audit"))
+	e, err := svc.Export.Get("example.com", "abhishek", "53156")
+	if err != nil {
+		t.Errorf("Expected nil but got %v", err)
+	}
+	_TestExport(e, t)
+	expected := "https://proxy.example.com/audit/mail/export/example.com/abhishek"
+	if e.URL() != expected {
+		t.Errorf(`Expected "%v" but got "%v"`, expected, e.URL())
+	}
+}
+
+func TestMailboxExportServiceWaitForCompletion(t *testing.T) {
+	defer gock.Off()
+	gock.New("https://apps-apis.google.com").
+		Get("/a/feeds/compliance/audit/mail/export/example.com/abhishek/53156").
+		Times(2).
+		Reply(200).
+		XML(exportXML)
+	gock.New("https://apps-apis.google.com").
+		Get("/a/feeds/compliance/audit/mail/export/example.com/abhishek/53156").
+		Reply(200).
+		XML(completedExportXML)
+
+	var statuses []RequestStatus
+	e, err := newTestService().Export.WaitForCompletion(context.Background(), "example.com", "abhishek", "53156", WaitOptions{
+		Backoff: Backoff{Initial: time.Millisecond, Max: time.Millisecond},
+		Progress: func(e *MailboxExport) {
+			statuses = append(statuses, e.Status)
+		},
+	})
+	if err != nil {
+		t.Errorf("Expected nil but got %v", err)
+	}
+	for _, test := range []struct {
+		actual   interface{}
+		expected interface{}
+	}{
+		{e.Status, CompletedStatus},
+		{len(statuses), 3},
+		{statuses[0], PendingStatus},
+		{statuses[1], PendingStatus},
+		{statuses[2], CompletedStatus},
+	} {
+		if test.actual != test.expected {
+			t.Errorf(`Expected "%v" but got "%v"`, test.expected, test.actual)
+		}
+	}
+}
+
+func TestMailboxExportServiceWaitForCompletionCanceled(t *testing.T) {
+	defer gock.Off()
+	gock.New("https://apps-apis.google.com").
+		Get("/a/feeds/compliance/audit/mail/export/example.com/abhishek/53156").
+		Reply(200).
+		XML(exportXML)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	e, err := newTestService().Export.WaitForCompletion(ctx, "example.com", "abhishek", "53156", WaitOptions{
+		Backoff:  Backoff{Initial: time.Hour},
+		Progress: func(e *MailboxExport) { cancel() },
+	})
+	if err != context.Canceled {
+		t.Errorf(`Expected "%v" but got "%v"`, context.Canceled, err)
+	}
+	if e == nil || e.Status != PendingStatus {
+		t.Errorf(`Expected "%v" but got "%v"`, PendingStatus, e)
+	}
+}
+
+func TestMailboxExportServiceWaitForCompletionRetryableError(t *testing.T) {
+	defer gock.Off()
+	gock.New("https://apps-apis.google.com").
+		Get("/a/feeds/compliance/audit/mail/export/example.com/abhishek/53156").
+		Reply(503).
+		BodyString("Unavailable")
+	gock.New("https://apps-apis.google.com").
+		Get("/a/feeds/compliance/audit/mail/export/example.com/abhishek/53156").
+		Reply(200).
+		XML(completedExportXML)
+
+	clock := &fakeClock{}
+	e, err := newTestService(WithClock(clock)).Export.WaitForCompletion(context.Background(), "example.com", "abhishek", "53156", WaitOptions{
+		Backoff: Backoff{Initial: time.Minute, Max: time.Minute},
+	})
+	if err != nil {
+		t.Errorf("Expected nil but got %v", err)
+	}
+	if e == nil || e.Status != CompletedStatus {
+		t.Errorf(`Expected "%v" but got "%v"`, CompletedStatus, e)
+	}
+	if len(clock.waits) != 1 || clock.waits[0] != time.Minute {
+		t.Errorf(`Expected "%v" but got "%v"`, []time.Duration{time.Minute}, clock.waits)
+	}
+}
+
+func TestMailboxExportServiceWaitForCompletionHTTPErrorResponse(t *testing.T) {
+	defer gock.Off()
+	gock.New("https://apps-apis.google.com").
+		Get("/a/feeds/compliance/audit/mail/export/example.com/abhishek/53156").
+		Reply(400).
+		BodyString("Omg")
+
+	e, err := newTestService().Export.WaitForCompletion(context.Background(), "example.com", "abhishek", "53156", WaitOptions{})
+	expected := "400 Bad Request: Omg"
+	if err == nil || err.Error() != expected {
+		t.Errorf(`Expected "%v" but got "%v"`, expected, err)
+	}
+	if e != nil {
+		t.Errorf("Expected nil but got %v", e)
+	}
+}
+
+func TestMailboxExportServiceListAll(t *testing.T) {
+	defer gock.Off()
+	gock.New("https://apps-apis.google.com").
+		Get("/a/feeds/compliance/audit/mail/export/example.com").
+		MatchParam("start", "53157").
+		MatchHeader("Authorization", "Bearer test").
+		Reply(200).
+		XML(exportsLastPageXML)
+	gock.New("https://apps-apis.google.com").
+		Get("/a/feeds/compliance/audit/mail/export/example.com").
+		MatchParam("fromDate", "2009-09-01 00:00").
+		MatchHeader("Authorization", "Bearer test").
+		MatchHeader("User-Agent", "google-api-go-client/0.5").
+		Reply(200).
+		XML(exportsXML)
+
+	fromDate := time.Date(2009, time.September, 1, 0, 0, 0, 0, time.UTC)
+	e, err := newTestService().Export.ListAll("example.com", &fromDate)
+	if err != nil {
+		t.Errorf("Expected nil but got %v", err)
+	}
+	for _, test := range []struct {
+		actual   interface{}
+		expected interface{}
+	}{
+		{len(e), 2},
+		{e[0].RequestID, "53156"},
+		{e[0].UserName, "abhishek"},
+		{e[0].Status, PendingStatus},
+		{e[1].RequestID, "53157"},
+		{e[1].UserName, "namrata"},
+		{e[1].Status, CompletedStatus},
+	} {
+		if test.actual != test.expected {
+			t.Errorf(`Expected "%v" but got "%v"`, test.expected, test.actual)
+		}
+	}
+}
+
+func TestMailboxExportServiceListAllNextLinkLoop(t *testing.T) {
+	defer gock.Off()
+	gock.New("https://apps-apis.google.com").
+		Get("/a/feeds/compliance/audit/mail/export/example.com").
+		MatchParam("start", "53157").
+		Reply(200).
+		XML(strings.Replace(exportsLastPageXML, "<entry>",
+			`<link rel="next" type="application/atom+xml" href="https://apps-apis.google.com/a/feeds/compliance/audit/mail/export/example.com"/>
+<entry>`, 1))
+	gock.New("https://apps-apis.google.com").
+		Get("/a/feeds/compliance/audit/mail/export/example.com").
+		Reply(200).
+		XML(exportsXML)
+
+	e, err := newTestService().Export.ListAll("example.com", nil)
+	if err != nil {
+		t.Errorf("Expected nil but got %v", err)
+	}
+	if len(e) != 2 {
+		t.Errorf("Expected 2 but got %v", len(e))
+	}
+}
+
+func TestMailboxExportServiceListAllHTTPErrorResponse(t *testing.T) {
+	defer gock.Off()
+	gock.New("https://apps-apis.google.com").
+		Get("/a/feeds/compliance/audit/mail/export/example.com").
+		Reply(400).
+		BodyString("Omg")
+
+	e, err := newTestService().Export.ListAll("example.com", nil)
+	expected := "400 Bad Request: Omg"
+	if err == nil || err.Error() != expected {
+		t.Errorf(`Expected "%v" but got "%v"`, expected, err)
+	}
+	if e != nil {
+		t.Errorf("Expected nil but got %v", e)
+	}
+}
+
+func TestMailboxExportServiceDelete(t *testing.T) {
+	defer gock.Off()
+	gock.New("https://apps-apis.google.com").
+		Delete("/a/feeds/compliance/audit/mail/export/example.com/abhishek/53156").
+		MatchHeader("Authorization", "Bearer test").
+		MatchHeader("User-Agent", "google-api-go-client/0.5").
+		Reply(200)
+
+	err := newTestService().Export.Delete("example.com", "abhishek", "53156")
+	if err != nil {
+		t.Errorf("Expected nil but got %v", err)
+	}
+}
+
+func TestMailboxExportServiceDeleteHTTPErrorResponse(t *testing.T) {
+	defer gock.Off()
+	gock.New("https://apps-apis.google.com").
+		Delete("/a/feeds/compliance/audit/mail/export/example.com/abhishek/53156").
+		Reply(400).
+		BodyString("Omg")
+
+	err := newTestService().Export.Delete("example.com", "abhishek", "53156")
+	expected := "400 Bad Request: Omg"
+	if err == nil || err.Error() != expected {
+		t.Errorf(`Expected "%v" but got "%v"`, expected, err)
+	}
+}
+
+const purgeExportsXML = `<?xml version="1.0" encoding="UTF-8"?>
+<feed xmlns="http://www.w3.org/2005/Atom" xmlns:apps="http://schemas.google.com/apps/2006">
+<id>https://apps-apis.google.com/a/feeds/compliance/audit/mail/export/example.com</id>
+<entry>
+  <id>https://apps-apis.google.com/a/feeds/compliance/audit/mail/export/example.com/abhishek/1</id>
+  <apps:property name="status" value="PENDING"/>
+  <apps:property name="requestId" value="1"/>
+</entry>
+<entry>
+  <id>https://apps-apis.google.com/a/feeds/compliance/audit/mail/export/example.com/abhishek/2</id>
+  <apps:property name="status" value="COMPLETED"/>
+  <apps:property name="requestId" value="2"/>
+  <apps:property name="completedDate" value="2009-09-11 02:40"/>
+</entry>
+<entry>
+  <id>https://apps-apis.google.com/a/feeds/compliance/audit/mail/export/example.com/namrata/3</id>
+  <apps:property name="status" value="COMPLETED"/>
+  <apps:property name="requestId" value="3"/>
+  <apps:property name="completedDate" value="2009-09-12 02:40"/>
+</entry>
+<entry>
+  <id>https://apps-apis.google.com/a/feeds/compliance/audit/mail/export/example.com/namrata/4</id>
+  <apps:property name="status" value="MARKED_DELETE"/>
+  <apps:property name="requestId" value="4"/>
+  <apps:property name="completedDate" value="2009-09-12 02:40"/>
+</entry>
+<entry>
+  <id>https://apps-apis.google.com/a/feeds/compliance/audit/mail/export/example.com/joe/5</id>
+  <apps:property name="status" value="COMPLETED"/>
+  <apps:property name="requestId" value="5"/>
+  <apps:property name="completedDate" value="2999-09-12 02:40"/>
+</entry>
+</feed>
+`
+
+func TestMailboxExportServicePurge(t *testing.T) {
+	defer gock.Off()
+	gock.New("https://apps-apis.google.com").
+		Get("/a/feeds/compliance/audit/mail/export/example.com").
+		Reply(200).
+		XML(purgeExportsXML)
+	gock.New("https://apps-apis.google.com").
+		Delete("/a/feeds/compliance/audit/mail/export/example.com/abhishek/2").
+		Reply(200)
+	gock.New("https://apps-apis.google.com").
+		Delete("/a/feeds/compliance/audit/mail/export/example.com/namrata/3").
+		Reply(400).
+		BodyString("Omg")
+
+	results, err := newTestService().Export.Purge(context.Background(), "example.com", 7*24*time.Hour)
+	if err != nil {
+		t.Errorf("Expected nil but got %v", err)
+	}
+	for _, test := range []struct {
+		actual   interface{}
+		expected interface{}
+	}{
+		{len(results), 2},
+		{results[0].Export.RequestID, "2"},
+		{results[0].Err, nil},
+		{results[1].Export.RequestID, "3"},
+		{results[1].Export.UserName, "namrata"},
+		{results[1].Err.Error(), "400 Bad Request: Omg"},
+	} {
+		if test.actual != test.expected {
+			t.Errorf(`Expected "%v" but got "%v"`, test.expected, test.actual)
+		}
+	}
+}
+
+func TestMailboxExportServicePurgeCanceled(t *testing.T) {
+	defer gock.Off()
+	gock.New("https://apps-apis.google.com").
+		Get("/a/feeds/compliance/audit/mail/export/example.com").
+		Reply(200).
+		XML(purgeExportsXML)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	results, err := newTestService().Export.Purge(ctx, "example.com", 0)
+	if err != context.Canceled {
+		t.Errorf(`Expected "%v" but got "%v"`, context.Canceled, err)
+	}
+	if len(results) != 0 {
+		t.Errorf("Expected 0 but got %v", len(results))
+	}
+}
+
+func TestMailboxExportServicePurgeCanceledWhileDeleting(t *testing.T) {
+	defer gock.Off()
+	ctx, cancel := context.WithCancel(context.Background())
+	gock.New("https://apps-apis.google.com").
+		Get("/a/feeds/compliance/audit/mail/export/example.com").
+		Reply(200).
+		XML(purgeExportsXML)
+	gock.New("https://apps-apis.google.com").
+		Delete("/a/feeds/compliance/audit/mail/export/example.com/abhishek/2").
+		AddMatcher(func(req *http.Request, ereq *gock.Request) (bool, error) {
+			cancel()
+			return true, nil
+		}).
+		Reply(200)
+
+	results, err := newTestService().Export.Purge(ctx, "example.com", 0)
+	if err != context.Canceled {
+		t.Errorf(`Expected "%v" but got "%v"`, context.Canceled, err)
+	}
+	if len(results) != 1 {
+		t.Errorf("Expected 1 but got %v", len(results))
+	}
+}
+
+func TestMailboxExportServiceCreateInvalidSearchQuery(t *testing.T) {
+	e, err := newTestService().Export.Create("example.com", "abhishek", MailboxExportOptions{
+		SearchQuery: "form:namrata@example.com",
+	})
+	expected := `invalid search query "form:namrata@example.com" at 0: unknown operator "form" (did you mean "from"?)`
+	if err == nil || err.Error() != expected {
+		t.Errorf(`Expected "%v" but got "%v"`, expected, err)
+	}
+	if e != nil {
+		t.Errorf("Expected nil but got %v", e)
+	}
+}
@@ -0,0 +1,206 @@
+package emailaudit
+
+import (
+	"encoding/xml"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const exportPath = "mail/export"
+
+// MailboxExport MailboxExport
+type MailboxExport struct {
+	DomainName        string
+	UserName          string
+	RequestID         string
+	Status            RequestStatus
+	BeginDate         *time.Time
+	EndDate           *time.Time
+	SearchQuery       string
+	IncludeDeleted    bool
+	PackageContent    MailboxExportPackageContent
+	AdminEmailAddress string
+	UserEmailAddress  string
+	RequestDate       *time.Time
+	CompletedDate     *time.Time
+	ExpiredDate       *time.Time
+	NumberOfFiles     int
+	FileURLs          []string
+	Updated           *time.Time
+
+	baseURL string
+}
+
+// MailboxExportOptions MailboxExportOptions
+type MailboxExportOptions struct {
+	BeginDate *time.Time
+	EndDate   *time.Time
+	// SearchQuery is a Gmail search query, which can be built with Query
+	SearchQuery    string
+	IncludeDeleted bool
+	PackageContent MailboxExportPackageContent
+}
+
+// NewMailboxExport returns new MailboxExport
+func NewMailboxExport(domainName string, userName string, options MailboxExportOptions) MailboxExport {
+	packageContent := options.PackageContent
+	if packageContent == "" {
+		packageContent = FullMessagePackageContent
+	}
+	e := MailboxExport{
+		DomainName:     domainName,
+		UserName:       userName,
+		BeginDate:      options.BeginDate,
+		EndDate:        options.EndDate,
+		SearchQuery:    options.SearchQuery,
+		IncludeDeleted: options.IncludeDeleted,
+		PackageContent: packageContent,
+	}
+	return e
+}
+
+func (req *MailboxExport) monitorWriteProperties() monitorWriteProperties {
+	m := monitorWriteProperties{}
+	if req.BeginDate != nil {
+		m.addProperty("beginDate", req.BeginDate)
+	}
+	if req.EndDate != nil {
+		m.addProperty("endDate", req.EndDate)
+	}
+	if req.SearchQuery != "" {
+		m.addProperty("searchQuery", req.SearchQuery)
+	}
+	m.addProperty("includeDeleted", req.IncludeDeleted)
+	m.addProperty("packageContent", req.PackageContent)
+	return m
+}
+
+func (req *MailboxExport) toXML() []byte {
+	return req.monitorWriteProperties().toXML()
+}
+
+// URL returns URL
+func (req *MailboxExport) URL() string {
+	return fmt.Sprintf("%v/%v/%v", endpoint(req.baseURL, exportPath), req.DomainName, req.UserName)
+}
+
+func exportFromXML(data []byte) (*MailboxExport, error) {
+	var v monitorReadProperties
+	if err := xml.Unmarshal(data, &v); err != nil {
+		return nil, err
+	}
+	e := v.toMailboxExport()
+	return &e, nil
+}
+
+func (m monitorReadProperties) toMailboxExport() MailboxExport {
+	e := MailboxExport{}
+	urls := fileURLs{}
+	for _, p := range m.AppProperties {
+		switch p.Name {
+		case "requestId":
+			e.RequestID = p.Value
+		case "status":
+			e.Status = RequestStatus(p.Value)
+		case "beginDate":
+			e.BeginDate = parseTime(p.Value)
+		case "endDate":
+			e.EndDate = parseTime(p.Value)
+		case "searchQuery":
+			e.SearchQuery = p.Value
+		case "includeDeleted":
+			e.IncludeDeleted = p.Value == "true"
+		case "packageContent":
+			e.PackageContent = MailboxExportPackageContent(p.Value)
+		case "adminEmailAddress":
+			e.AdminEmailAddress = p.Value
+		case "userEmailAddress":
+			e.UserEmailAddress = p.Value
+		case "requestDate":
+			e.RequestDate = parseTime(p.Value)
+		case "completedDate":
+			e.CompletedDate = parseTime(p.Value)
+		case "expiredDate":
+			e.ExpiredDate = parseTime(p.Value)
+		case "numberOfFiles":
+			e.NumberOfFiles, _ = strconv.Atoi(p.Value)
+		default:
+			urls.set(p.Name, p.Value)
+		}
+	}
+	e.FileURLs = urls.list(e.NumberOfFiles)
+	e.DomainName, e.UserName = m.domainAndUser(exportPath, e.UserEmailAddress, e.RequestID)
+	e.Updated = m.Updated
+	return e
+}
+
+// maxFileURLs bounds fileUrl{N} indices, which come from the server
+const maxFileURLs = 10000
+
+// fileURLs collects values of fileUrl{N} properties by N
+type fileURLs map[int]string
+
+func (u fileURLs) set(name string, value string) {
+	if !strings.HasPrefix(name, "fileUrl") {
+		return
+	}
+	i, err := strconv.Atoi(strings.TrimPrefix(name, "fileUrl"))
+	if err != nil || i < 0 || i >= maxFileURLs {
+		return
+	}
+	u[i] = value
+}
+
+// list returns the URLs indexed by N, skipping indices out of numberOfFiles when it is known
+func (u fileURLs) list(numberOfFiles int) []string {
+	size := 0
+	for i := range u {
+		if (numberOfFiles <= 0 || i < numberOfFiles) && i >= size {
+			size = i + 1
+		}
+	}
+	if size == 0 {
+		return nil
+	}
+	urls := make([]string, size)
+	for i, value := range u {
+		if i < size {
+			urls[i] = value
+		}
+	}
+	return urls
+}
+
+// domainAndUserFromID parses {baseURL}/{path}/{domain}/{user}/{requestId} entry ID.
+// The user is the local part of userEmailAddress when given, as domain-level IDs
+// such as {baseURL}/{path}/{domain}/{requestId} do not contain it.
+// Otherwise it is the segment following the domain unless the segment is requestID.
+func domainAndUserFromID(id string, path string, userEmailAddress string, requestID string) (string, string) {
+	var domainName, userName string
+	if i := strings.Index(id, "/"+path+"/"); i >= 0 {
+		urlparts := strings.Split(id[i+len(path)+2:], "/")
+		domainName = urlparts[0]
+		if len(urlparts) > 1 && (requestID == "" || urlparts[1] != requestID) {
+			userName = urlparts[1]
+		}
+	}
+	if userEmailAddress != "" {
+		userName = strings.Split(userEmailAddress, "@")[0]
+	}
+	return domainName, userName
+}
+
+func parseTime(value string) *time.Time {
+	d, err := time.Parse(timeFormat, value)
+	if err != nil {
+		return nil
+	}
+	return &d
+}
+
+func fromDateQuery(fromDate *time.Time) string {
+	return url.Values{"fromDate": {fromDate.UTC().Format(timeFormat)}}.Encode()
+}
@@ -0,0 +1,244 @@
+package emailaudit
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMailboxExportToXML(t *testing.T) {
+	loc, _ := time.LoadLocation("Asia/Tokyo")
+	beginDate := time.Date(2016, time.September, 1, 0, 0, 0, 0, loc)
+	endDate := time.Date(2016, time.October, 30, 23, 59, 59, 0, loc)
+	for _, test := range []struct {
+		export      MailboxExport
+		expectedXML string
+	}{
+		{NewMailboxExport("example.com", "abhishek", MailboxExportOptions{}),
+			`<atom:entry xmlns:atom="http://www.w3.org/2005/Atom" xmlns:apps="http://schemas.google.com/apps/2006">
+  <apps:property name="includeDeleted" value="false"></apps:property>
+  <apps:property name="packageContent" value="FULL_MESSAGE"></apps:property>
+</atom:entry>`},
+		{NewMailboxExport("example.com", "abhishek", MailboxExportOptions{
+			BeginDate:      &beginDate,
+			EndDate:        &endDate,
+			SearchQuery:    "in:chats",
+			IncludeDeleted: true,
+			PackageContent: HeaderOnlyPackageContent,
+		}),
+			`<atom:entry xmlns:atom="http://www.w3.org/2005/Atom" xmlns:apps="http://schemas.google.com/apps/2006">
+  <apps:property name="beginDate" value="2016-08-31 15:00"></apps:property>
+  <apps:property name="endDate" value="2016-10-30 14:59"></apps:property>
+  <apps:property name="searchQuery" value="in:chats"></apps:property>
+  <apps:property name="includeDeleted" value="true"></apps:property>
+  <apps:property name="packageContent" value="HEADER_ONLY"></apps:property>
+</atom:entry>`},
+	} {
+		x := string(test.export.toXML())
+		if x != test.expectedXML {
+			t.Errorf(`Expected "%v" but got "%v"`, test.expectedXML, x)
+		}
+	}
+}
+
+func TestMailboxExportURL(t *testing.T) {
+	e := MailboxExport{
+		UserName:   "abhishek",
+		DomainName: "example.com",
+	}
+	expected := "https://apps-apis.google.com/a/feeds/compliance/audit/mail/export/example.com/abhishek"
+	actual := e.URL()
+	if expected != actual {
+		t.Errorf(`Expected "%v" but got "%v"`, expected, actual)
+	}
+}
+
+const exportXML = `<entry xmlns='http://www.w3.org/2005/Atom' xmlns:apps='http://schemas.google.com/apps/2006'>
+  <id>https://apps-apis.google.com/a/feeds/compliance/audit/mail/export/example.com/abhishek/53156</id>
+  <updated>2009-09-10T09:16:46.213Z</updated>
+  <link rel='self' type='application/atom+xml' href='https://apps-apis.google.com/a/feeds/compliance/audit/mail/export/example.com/abhishek/53156' />
+  <link rel='edit' type='application/atom+xml' href='https://apps-apis.google.com/a/feeds/compliance/audit/mail/export/example.com/abhishek/53156' />
+  <apps:property name='status' value='PENDING' />
+  <apps:property name='packageContent' value='FULL_MESSAGE' />
+  <apps:property name='includeDeleted' value='true' />
+  <apps:property name='searchQuery' value='in:chats' />
+  <apps:property name='beginDate' value='2009-06-15 00:00' />
+  <apps:property name='endDate' value='2009-06-30 23:20' />
+  <apps:property name='requestDate' value='2009-09-10 09:16' />
+  <apps:property name='adminEmailAddress' value='admin@example.com' />
+  <apps:property name='requestId' value='53156' />
+  <apps:property name='userEmailAddress' value='abhishek@example.com' />
+</entry>`
+
+func TestMailboxExportFromXML(t *testing.T) {
+	e, err := exportFromXML([]byte(exportXML))
+	if err != nil {
+		t.Errorf("Expected nil but got %v", err)
+	}
+	_TestExport(e, t)
+}
+
+func _TestExport(e *MailboxExport, t *testing.T) {
+	for _, test := range []struct {
+		actual   interface{}
+		expected interface{}
+	}{
+		{e.RequestID, "53156"},
+		{e.Status, PendingStatus},
+		{e.PackageContent, FullMessagePackageContent},
+		{e.IncludeDeleted, true},
+		{e.SearchQuery, "in:chats"},
+		{e.DomainName, "example.com"},
+		{e.UserName, "abhishek"},
+		{e.AdminEmailAddress, "admin@example.com"},
+		{e.UserEmailAddress, "abhishek@example.com"},
+		{e.BeginDate.String(), time.Date(2009, time.June, 15, 0, 0, 0, 0, time.UTC).String()},
+		{e.EndDate.String(), time.Date(2009, time.June, 30, 23, 20, 0, 0, time.UTC).String()},
+		{e.RequestDate.String(), time.Date(2009, time.September, 10, 9, 16, 0, 0, time.UTC).String()},
+		{e.CompletedDate == nil, true},
+		{e.Updated.UnixNano(), time.Date(2009, time.September, 10, 9, 16, 46, 213000000, time.UTC).UnixNano()},
+	} {
+		if test.actual != test.expected {
+			t.Errorf(`Expected "%v" but got "%v"`, test.expected, test.actual)
+		}
+	}
+}
+
+const completedExportXML = `<entry xmlns='http://www.w3.org/2005/Atom' xmlns:apps='http://schemas.google.com/apps/2006'>
+  <id>https://apps-apis.google.com/a/feeds/compliance/audit/mail/export/example.com/abhishek/53156</id>
+  <updated>2009-09-11T09:16:46.213Z</updated>
+  <apps:property name='status' value='COMPLETED' />
+  <apps:property name='packageContent' value='FULL_MESSAGE' />
+  <apps:property name='requestDate' value='2009-09-10 09:16' />
+  <apps:property name='completedDate' value='2009-09-11 02:40' />
+  <apps:property name='requestId' value='53156' />
+  <apps:property name='numberOfFiles' value='2' />
+  <apps:property name='fileUrl1' value='https://apps-apis.google.com/a/data/compliance/audit/OQAAABW1_1' />
+  <apps:property name='fileUrl0' value='https://apps-apis.google.com/a/data/compliance/audit/OQAAABW1_0' />
+</entry>`
+
+func TestCompletedMailboxExportFromXML(t *testing.T) {
+	e, err := exportFromXML([]byte(completedExportXML))
+	if err != nil {
+		t.Errorf("Expected nil but got %v", err)
+	}
+	for _, test := range []struct {
+		actual   interface{}
+		expected interface{}
+	}{
+		{e.Status, CompletedStatus},
+		{e.NumberOfFiles, 2},
+		{len(e.FileURLs), 2},
+		{e.FileURLs[0], "https://apps-apis.google.com/a/data/compliance/audit/OQAAABW1_0"},
+		{e.FileURLs[1], "https://apps-apis.google.com/a/data/compliance/audit/OQAAABW1_1"},
+		{e.CompletedDate.String(), time.Date(2009, time.September, 11, 2, 40, 0, 0, time.UTC).String()},
+	} {
+		if test.actual != test.expected {
+			t.Errorf(`Expected "%v" but got "%v"`, test.expected, test.actual)
+		}
+	}
+}
+
+const domainLevelExportXML = `<entry xmlns='http://www.w3.org/2005/Atom' xmlns:apps='http://schemas.google.com/apps/2006'>
+  <id>https://apps-apis.google.com/a/feeds/compliance/audit/mail/export/example.com/53156</id>
+  <updated>2009-09-10T09:16:46.213Z</updated>
+  <apps:property name='status' value='PENDING' />
+  <apps:property name='requestId' value='53156' />
+  <apps:property name='userEmailAddress' value='abhishek@example.com' />
+</entry>`
+
+func TestMailboxExportFromXMLDomainLevelID(t *testing.T) {
+	withoutUser := strings.Replace(domainLevelExportXML, "<apps:property name='userEmailAddress' value='abhishek@example.com' />", "", 1)
+	for _, test := range []struct {
+		data     string
+		expected string
+	}{
+		{domainLevelExportXML, "abhishek"},
+		{withoutUser, ""},
+		{exportXML, "abhishek"},
+	} {
+		e, err := exportFromXML([]byte(test.data))
+		if err != nil {
+			t.Errorf("Expected nil but got %v", err)
+			continue
+		}
+		if e.DomainName != "example.com" || e.UserName != test.expected {
+			t.Errorf(`Expected "example.com" "%v" but got "%v" "%v"`, test.expected, e.DomainName, e.UserName)
+		}
+	}
+}
+
+func TestMailboxExportFromXMLFileURLIndices(t *testing.T) {
+	entry := func(properties string) []byte {
+		return []byte(`<entry xmlns='http://www.w3.org/2005/Atom' xmlns:apps='http://schemas.google.com/apps/2006'>` + properties + `</entry>`)
+	}
+	for _, test := range []struct {
+		data     []byte
+		expected []string
+	}{
+		{entry(`<apps:property name='numberOfFiles' value='2' />
+  <apps:property name='fileUrl0' value='a' />
+  <apps:property name='fileUrl5' value='b' />
+  <apps:property name='fileUrl900000000' value='c' />`), []string{"a"}},
+		{entry(`<apps:property name='fileUrl2' value='b' />
+  <apps:property name='fileUrl0' value='a' />
+  <apps:property name='fileUrl900000000' value='c' />
+  <apps:property name='fileUrl-1' value='d' />`), []string{"a", "", "b"}},
+		{entry(`<apps:property name='numberOfFiles' value='900000000' />
+  <apps:property name='fileUrl900000000' value='c' />`), nil},
+	} {
+		e, err := exportFromXML(test.data)
+		if err != nil {
+			t.Errorf("Expected nil but got %v", err)
+			continue
+		}
+		if !reflect.DeepEqual(e.FileURLs, test.expected) {
+			t.Errorf(`Expected "%v" but got "%v"`, test.expected, e.FileURLs)
+		}
+	}
+}
+
+func TestExportFromXMLError(t *testing.T) {
+	e, err := exportFromXML([]byte("<foo />"))
+	expected := "expected element type <entry> but have <foo>"
+	if err.Error() != expected {
+		t.Errorf(`Expected "%v" but got "%v"`, expected, err.Error())
+	}
+	if e != nil {
+		t.Errorf("Expected nil but got %v", e)
+	}
+}
+
+const exportsXML = `<?xml version="1.0" encoding="UTF-8"?>
+<feed xmlns="http://www.w3.org/2005/Atom" xmlns:openSearch="http://a9.com/-/spec/opensearchrss/1.0/" xmlns:apps="http://schemas.google.com/apps/2006">
+<id>https://apps-apis.google.com/a/feeds/compliance/audit/mail/export/example.com</id>
+<updated>2009-09-10T09:16:46.213Z</updated>
+<link rel="self" type="application/atom+xml" href="https://apps-apis.google.com/a/feeds/compliance/audit/mail/export/example.com"/>
+<link rel="next" type="application/atom+xml" href="https://apps-apis.google.com/a/feeds/compliance/audit/mail/export/example.com?start=53157"/>
+<openSearch:startIndex>1</openSearch:startIndex>
+<entry>
+  <id>https://apps-apis.google.com/a/feeds/compliance/audit/mail/export/example.com/abhishek/53156</id>
+  <updated>2009-09-10T09:16:46.213Z</updated>
+  <apps:property name="status" value="PENDING"/>
+  <apps:property name="requestId" value="53156"/>
+  <apps:property name="userEmailAddress" value="abhishek@example.com"/>
+</entry>
+</feed>
+`
+
+const exportsLastPageXML = `<?xml version="1.0" encoding="UTF-8"?>
+<feed xmlns="http://www.w3.org/2005/Atom" xmlns:openSearch="http://a9.com/-/spec/opensearchrss/1.0/" xmlns:apps="http://schemas.google.com/apps/2006">
+<id>https://apps-apis.google.com/a/feeds/compliance/audit/mail/export/example.com</id>
+<updated>2009-09-10T09:16:46.213Z</updated>
+<link rel="self" type="application/atom+xml" href="https://apps-apis.google.com/a/feeds/compliance/audit/mail/export/example.com?start=53157"/>
+<entry>
+  <id>https://apps-apis.google.com/a/feeds/compliance/audit/mail/export/example.com/namrata/53157</id>
+  <updated>2009-09-11T09:16:46.213Z</updated>
+  <apps:property name="status" value="COMPLETED"/>
+  <apps:property name="requestId" value="53157"/>
+  <apps:property name="completedDate" value="2009-09-11 02:40"/>
+  <apps:property name="userEmailAddress" value="namrata@example.com"/>
+</entry>
+</feed>
+`
@@ -0,0 +1,89 @@
+// Package exportcrypt decrypts mailbox export files which Google encrypted
+// with the public key uploaded by emailaudit.PublicKeyService.
+package exportcrypt
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"io"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"github.com/ProtonMail/go-crypto/openpgp/armor"
+)
+
+const armorHeader = "-----BEGIN PGP MESSAGE-----"
+
+// PassphraseFunc returns the passphrase of an encrypted private key
+type PassphraseFunc func() ([]byte, error)
+
+// Decrypter Decrypter
+type Decrypter struct {
+	keyring openpgp.EntityList
+}
+
+// NewDecrypter returns new Decrypter with the ASCII armored private key.
+// passphrase is called once if the private key is encrypted and may be nil otherwise.
+func NewDecrypter(armoredPrivateKey io.Reader, passphrase PassphraseFunc) (*Decrypter, error) {
+	keyring, err := openpgp.ReadArmoredKeyRing(armoredPrivateKey)
+	if err != nil {
+		return nil, err
+	}
+	var pass []byte
+	for _, e := range keyring {
+		if e.PrivateKey == nil {
+			return nil, errors.New("exportcrypt: private key is required")
+		}
+		keys := []*openpgp.Key{{Entity: e, PrivateKey: e.PrivateKey}}
+		for i := range e.Subkeys {
+			keys = append(keys, &openpgp.Key{Entity: e, PrivateKey: e.Subkeys[i].PrivateKey})
+		}
+		for _, k := range keys {
+			if k.PrivateKey == nil || !k.PrivateKey.Encrypted {
+				continue
+			}
+			if pass == nil {
+				if passphrase == nil {
+					return nil, errors.New("exportcrypt: private key is encrypted but no passphrase is given")
+				}
+				if pass, err = passphrase(); err != nil {
+					return nil, err
+				}
+			}
+			if err := k.PrivateKey.Decrypt(pass); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return &Decrypter{keyring: keyring}, nil
+}
+
+// NewReader returns a reader of the plaintext of r.
+// r may be either binary or ASCII armored and is decrypted as it is read.
+// Integrity errors are returned by Read once the end of the message is reached.
+func (d *Decrypter) NewReader(r io.Reader) (io.Reader, error) {
+	br := bufio.NewReader(r)
+	head, _ := br.Peek(len(armorHeader))
+	var in io.Reader = br
+	if bytes.Equal(head, []byte(armorHeader)) {
+		block, err := armor.Decode(br)
+		if err != nil {
+			return nil, err
+		}
+		in = block.Body
+	}
+	md, err := openpgp.ReadMessage(in, d.keyring, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	return md.UnverifiedBody, nil
+}
+
+// NewReader returns a reader of the plaintext of r decrypted with the ASCII armored private key
+func NewReader(r io.Reader, armoredPrivateKey io.Reader, passphrase PassphraseFunc) (io.Reader, error) {
+	d, err := NewDecrypter(armoredPrivateKey, passphrase)
+	if err != nil {
+		return nil, err
+	}
+	return d.NewReader(r)
+}
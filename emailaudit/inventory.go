@@ -0,0 +1,159 @@
+package emailaudit
+
+import (
+	"context"
+	"encoding/csv"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+)
+
+const defaultInventoryConcurrency = 4
+
+// UserSource lists source user names of a domain to inventory
+type UserSource interface {
+	Users(ctx context.Context, domainName string) ([]string, error)
+}
+
+// UserSourceFunc adapts a function, such as a directory lookup, to UserSource
+type UserSourceFunc func(ctx context.Context, domainName string) ([]string, error)
+
+// Users calls f
+func (f UserSourceFunc) Users(ctx context.Context, domainName string) ([]string, error) {
+	return f(ctx, domainName)
+}
+
+// StaticUsers is a UserSource of a fixed list of user names
+type StaticUsers []string
+
+// Users returns the user names
+func (u StaticUsers) Users(ctx context.Context, domainName string) ([]string, error) {
+	return []string(u), nil
+}
+
+// CSVUsers is a UserSource reading user names from a column of a CSV file.
+// Email addresses are accepted and their domain part is dropped.
+type CSVUsers struct {
+	Path string
+	// Column is the zero-based index of the column containing user names
+	Column int
+	// Header skips the first row
+	Header bool
+}
+
+// Users reads user names from the CSV file
+func (u CSVUsers) Users(ctx context.Context, domainName string) ([]string, error) {
+	f, err := os.Open(u.Path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	r := csv.NewReader(f)
+	r.FieldsPerRecord = -1
+	var users []string
+	for row := 0; ; row++ {
+		record, err := r.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if (row == 0 && u.Header) || u.Column >= len(record) {
+			continue
+		}
+		users = append(users, record[u.Column])
+	}
+	return users, nil
+}
+
+// InventoryOptions InventoryOptions
+type InventoryOptions struct {
+	// Concurrency is the number of users listed in parallel (default 4)
+	Concurrency int
+}
+
+// Inventory is the email monitors of every user in a domain
+type Inventory struct {
+	DomainName string
+	// Monitors are sorted by source and destination user name
+	Monitors []MailMonitor
+	// Errors are sorted by source user name
+	Errors []InventoryError
+}
+
+// InventoryError is the failure to list email monitors of a source user
+type InventoryError struct {
+	SourceUserName string
+	Err            error
+}
+
+func (e InventoryError) Error() string {
+	return e.SourceUserName + ": " + e.Err.Error()
+}
+
+// Inventory lists email monitors of every user returned by users.
+// A failure to list one user is reported in Errors and does not stop the others.
+func (svc *MailMonitorService) Inventory(ctx context.Context, domain string, users UserSource, options InventoryOptions) (*Inventory, error) {
+	names, err := users.Users(ctx, domain)
+	if err != nil {
+		return nil, err
+	}
+	names = normalizeUserNames(names)
+	concurrency := options.Concurrency
+	if concurrency <= 0 {
+		concurrency = defaultInventoryConcurrency
+	}
+	inventory := &Inventory{DomainName: domain, Monitors: []MailMonitor{}}
+	var mu sync.Mutex
+	parallel(len(names), concurrency, func(i int) {
+		var monitors []MailMonitor
+		err := ctx.Err()
+		if err == nil {
+			monitors, err = svc.ListContext(ctx, domain, names[i])
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		if err != nil {
+			inventory.Errors = append(inventory.Errors, InventoryError{SourceUserName: names[i], Err: err})
+			return
+		}
+		for _, m := range monitors {
+			if m.SourceUserName == "" {
+				m.SourceUserName = names[i]
+			}
+			if m.DomainName == "" {
+				m.DomainName = domain
+			}
+			inventory.Monitors = append(inventory.Monitors, m)
+		}
+	})
+	sort.Slice(inventory.Monitors, func(i, j int) bool {
+		a, b := inventory.Monitors[i], inventory.Monitors[j]
+		if a.SourceUserName != b.SourceUserName {
+			return a.SourceUserName < b.SourceUserName
+		}
+		return a.DestUserName < b.DestUserName
+	})
+	sort.Slice(inventory.Errors, func(i, j int) bool {
+		return inventory.Errors[i].SourceUserName < inventory.Errors[j].SourceUserName
+	})
+	return inventory, ctx.Err()
+}
+
+// normalizeUserNames drops domain parts, blanks and duplicates
+func normalizeUserNames(names []string) []string {
+	seen := map[string]bool{}
+	var ret []string
+	for _, name := range names {
+		name = strings.TrimSpace(strings.Split(name, "@")[0])
+		if name == "" || seen[name] {
+			continue
+		}
+		seen[name] = true
+		ret = append(ret, name)
+	}
+	return ret
+}
@@ -0,0 +1,110 @@
+package emailaudit
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	gock "gopkg.in/h2non/gock.v1"
+)
+
+func TestMailMonitorServiceInventory(t *testing.T) {
+	defer gock.Off()
+	gock.New("https://apps-apis.google.com").
+		Get("/a/feeds/compliance/audit/mail/monitor/example.com/abhishek").
+		MatchHeader("Authorization", "Bearer test").
+		Reply(200).
+		XML(monitorsXML)
+	gock.New("https://apps-apis.google.com").
+		Get("/a/feeds/compliance/audit/mail/monitor/example.com/bob").
+		Reply(400).
+		BodyString("Omg")
+	gock.New("https://apps-apis.google.com").
+		Get("/a/feeds/compliance/audit/mail/monitor/example.com/zoe").
+		Reply(200).
+		XML(`<feed xmlns="http://www.w3.org/2005/Atom"></feed>`)
+
+	users := StaticUsers{"zoe", "bob@example.com", "abhishek", "zoe", ""}
+	inventory, err := newTestService().MailMonitor.Inventory(context.Background(), "example.com", users, InventoryOptions{Concurrency: 2})
+	if err != nil {
+		t.Fatalf("Expected nil but got %v", err)
+	}
+	var monitors []string
+	for _, m := range inventory.Monitors {
+		monitors = append(monitors, m.DomainName+"/"+m.SourceUserName+"/"+m.DestUserName)
+	}
+	for _, test := range []struct {
+		actual   interface{}
+		expected interface{}
+	}{
+		{inventory.DomainName, "example.com"},
+		{monitors, []string{"example.com/abhishek/joe", "example.com/abhishek/namrata"}},
+		{len(inventory.Errors), 1},
+		{inventory.Errors[0].SourceUserName, "bob"},
+		{inventory.Errors[0].Error(), "bob: 400 Bad Request: Omg"},
+	} {
+		if !reflect.DeepEqual(test.actual, test.expected) {
+			t.Errorf(`Expected "%v" but got "%v"`, test.expected, test.actual)
+		}
+	}
+}
+
+func TestMailMonitorServiceInventoryUserSourceError(t *testing.T) {
+	users := UserSourceFunc(func(ctx context.Context, domainName string) ([]string, error) {
+		return nil, os.ErrNotExist
+	})
+	inventory, err := newTestService().MailMonitor.Inventory(context.Background(), "example.com", users, InventoryOptions{})
+	if err != os.ErrNotExist {
+		t.Errorf(`Expected "%v" but got "%v"`, os.ErrNotExist, err)
+	}
+	if inventory != nil {
+		t.Errorf("Expected nil but got %v", inventory)
+	}
+}
+
+func TestMailMonitorServiceInventoryContextCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	inventory, err := newTestService().MailMonitor.Inventory(ctx, "example.com", StaticUsers{"abhishek"}, InventoryOptions{})
+	if err != context.Canceled {
+		t.Errorf(`Expected "%v" but got "%v"`, context.Canceled, err)
+	}
+	if len(inventory.Errors) != 1 || inventory.Errors[0].Err != context.Canceled {
+		t.Errorf("Expected canceled error of abhishek but got %v", inventory.Errors)
+	}
+}
+
+func TestCSVUsers(t *testing.T) {
+	dir, err := ioutil.TempDir("", "inventory")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "users.csv")
+	data := "name,email\nAbhishek,abhishek@example.com\nJoe,joe@example.com\nshort\n"
+	if err := ioutil.WriteFile(path, []byte(data), 0600); err != nil {
+		t.Fatal(err)
+	}
+	for _, test := range []struct {
+		source   CSVUsers
+		expected []string
+	}{
+		{CSVUsers{Path: path, Column: 1, Header: true}, []string{"abhishek@example.com", "joe@example.com"}},
+		{CSVUsers{Path: path}, []string{"name", "Abhishek", "Joe", "short"}},
+	} {
+		users, err := test.source.Users(context.Background(), "example.com")
+		if err != nil {
+			t.Errorf("Expected nil but got %v", err)
+		}
+		if !reflect.DeepEqual(users, test.expected) {
+			t.Errorf(`Expected "%v" but got "%v"`, test.expected, users)
+		}
+	}
+	_, err = CSVUsers{Path: filepath.Join(dir, "missing.csv")}.Users(context.Background(), "example.com")
+	if !os.IsNotExist(err) {
+		t.Errorf("Expected not exist error but got %v", err)
+	}
+}
@@ -0,0 +1,190 @@
+// Package mbox reads messages of a decrypted mailbox export in mbox format.
+package mbox
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/mail"
+)
+
+// Format Format
+type Format int
+
+const (
+	// MboxRD unquotes one level of every ">From " line (">>From " becomes ">From ")
+	MboxRD Format = iota
+	// MboxO unquotes only ">From " lines
+	MboxO
+)
+
+var separator = []byte("From ")
+
+// Reader reads messages from mbox input of arbitrary size.
+// Only the line being read is held in memory.
+type Reader struct {
+	// Format is the quoting convention of "From " lines in message bodies (default MboxRD)
+	Format Format
+
+	r         *bufio.Reader
+	offset    int64
+	unread    []byte
+	unreadOff int64
+	eof       bool
+	err       error
+	pending   []byte
+	pendOff   int64
+	msgOffset int64
+	cur       *messageReader
+}
+
+// NewReader returns new Reader
+func NewReader(r io.Reader) *Reader {
+	return &Reader{r: bufio.NewReader(r), msgOffset: -1}
+}
+
+// Next returns the next message, or io.EOF when no more messages are available.
+// The body of the previous message is discarded.
+func (r *Reader) Next() (*mail.Message, error) {
+	raw, err := r.NextRaw()
+	if err != nil {
+		return nil, err
+	}
+	msg, err := mail.ReadMessage(raw)
+	if err != nil {
+		return nil, fmt.Errorf("mbox: message at offset %d: %v", r.msgOffset, err)
+	}
+	return msg, nil
+}
+
+// NextRaw returns a reader of the next unquoted message including its headers,
+// or io.EOF when no more messages are available.
+// The previous message is discarded.
+func (r *Reader) NextRaw() (io.Reader, error) {
+	if r.cur != nil {
+		if _, err := io.Copy(ioutil.Discard, r.cur); err != nil {
+			return nil, err
+		}
+		r.cur = nil
+	}
+	if r.pending == nil {
+		for {
+			line, off, err := r.readLine()
+			if err != nil {
+				return nil, err
+			}
+			if bytes.HasPrefix(line, separator) {
+				r.pending, r.pendOff = line, off
+				break
+			}
+		}
+	}
+	r.msgOffset = r.pendOff
+	r.pending = nil
+	r.cur = &messageReader{r: r}
+	return r.cur, nil
+}
+
+// Offset returns the byte offset of the "From " line of the message last returned by Next
+func (r *Reader) Offset() int64 {
+	return r.msgOffset
+}
+
+func (r *Reader) readLine() ([]byte, int64, error) {
+	if r.unread != nil {
+		line, off := r.unread, r.unreadOff
+		r.unread = nil
+		return line, off, nil
+	}
+	if r.err != nil {
+		return nil, r.offset, r.err
+	}
+	off := r.offset
+	line, err := r.r.ReadBytes('\n')
+	r.offset += int64(len(line))
+	if err != nil {
+		r.err = err
+		if len(line) == 0 {
+			return nil, off, err
+		}
+	}
+	return line, off, nil
+}
+
+func (r *Reader) unquote(line []byte) []byte {
+	i := 0
+	for i < len(line) && line[i] == '>' {
+		i++
+	}
+	if i == 0 || !bytes.HasPrefix(line[i:], separator) {
+		return line
+	}
+	if r.Format == MboxO && i > 1 {
+		return line
+	}
+	return line[1:]
+}
+
+type messageReader struct {
+	r    *Reader
+	buf  []byte
+	done bool
+}
+
+func (m *messageReader) Read(p []byte) (int, error) {
+	for len(m.buf) == 0 {
+		if m.done {
+			return 0, io.EOF
+		}
+		if err := m.fill(); err != nil {
+			return 0, err
+		}
+	}
+	n := copy(p, m.buf)
+	m.buf = m.buf[n:]
+	return n, nil
+}
+
+func (m *messageReader) fill() error {
+	r := m.r
+	line, off, err := r.readLine()
+	if err != nil {
+		return m.end(err)
+	}
+	if bytes.HasPrefix(line, separator) {
+		r.pending, r.pendOff = line, off
+		m.done = true
+		return nil
+	}
+	if isBlank(line) {
+		// the blank line preceding the next "From " line belongs to the mbox, not the message
+		next, nextOff, err := r.readLine()
+		if err != nil {
+			return m.end(err)
+		}
+		if bytes.HasPrefix(next, separator) {
+			r.pending, r.pendOff = next, nextOff
+			m.done = true
+			return nil
+		}
+		r.unread, r.unreadOff = next, nextOff
+		m.buf = line
+		return nil
+	}
+	m.buf = r.unquote(line)
+	return nil
+}
+
+func (m *messageReader) end(err error) error {
+	m.done = true
+	if err == io.EOF {
+		return nil
+	}
+	return err
+}
+
+func isBlank(line []byte) bool {
+	return len(bytes.TrimRight(line, "\r\n")) == 0
+}
@@ -0,0 +1,152 @@
+package mbox
+
+import (
+	"io"
+	"io/ioutil"
+	"strings"
+	"testing"
+)
+
+var mboxData = `From abhishek@example.com Thu Sep 10 09:16:46 2009
+Message-ID: <1@example.com>
+Subject: first
+
+Hello
+>From the quoted line
+>>From the double quoted line
+
+From namrata@example.com Thu Sep 10 09:17:46 2009
+Message-ID: <2@example.com>
+Subject: second
+
+` + strings.Repeat("x", 8192) + `
+
+From joe@example.com Thu Sep 10 09:18:46 2009
+Message-ID: <3@example.com>
+Subject: third
+
+Bye
+`
+
+func readAll(t *testing.T, r *Reader) ([]string, []string, []int64) {
+	var ids, bodies []string
+	var offsets []int64
+	for {
+		msg, err := r.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("Expected nil but got %v", err)
+		}
+		body, _ := ioutil.ReadAll(msg.Body)
+		ids = append(ids, msg.Header.Get("Message-ID"))
+		bodies = append(bodies, string(body))
+		offsets = append(offsets, r.Offset())
+	}
+	return ids, bodies, offsets
+}
+
+func TestReaderMboxRD(t *testing.T) {
+	ids, bodies, offsets := readAll(t, NewReader(strings.NewReader(mboxData)))
+	for _, test := range []struct {
+		actual   interface{}
+		expected interface{}
+	}{
+		{len(ids), 3},
+		{ids[0], "<1@example.com>"},
+		{ids[1], "<2@example.com>"},
+		{ids[2], "<3@example.com>"},
+		{bodies[0], "Hello\nFrom the quoted line\n>From the double quoted line\n"},
+		{bodies[1], strings.Repeat("x", 8192) + "\n"},
+		{bodies[2], "Bye\n"},
+		{offsets[0], int64(0)},
+		{offsets[1], int64(strings.Index(mboxData, "From namrata"))},
+		{offsets[2], int64(strings.Index(mboxData, "From joe"))},
+	} {
+		if test.actual != test.expected {
+			t.Errorf(`Expected "%v" but got "%v"`, test.expected, test.actual)
+		}
+	}
+}
+
+func TestReaderMboxO(t *testing.T) {
+	r := NewReader(strings.NewReader(mboxData))
+	r.Format = MboxO
+	_, bodies, _ := readAll(t, r)
+	expected := "Hello\nFrom the quoted line\n>>From the double quoted line\n"
+	if bodies[0] != expected {
+		t.Errorf(`Expected "%v" but got "%v"`, expected, bodies[0])
+	}
+}
+
+func TestReaderSkipsUnreadBodies(t *testing.T) {
+	r := NewReader(strings.NewReader(mboxData))
+	var subjects []string
+	for {
+		msg, err := r.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("Expected nil but got %v", err)
+		}
+		subjects = append(subjects, msg.Header.Get("Subject"))
+	}
+	expected := "first,second,third"
+	if strings.Join(subjects, ",") != expected {
+		t.Errorf(`Expected "%v" but got "%v"`, expected, strings.Join(subjects, ","))
+	}
+}
+
+func TestReaderNextRaw(t *testing.T) {
+	r := NewReader(strings.NewReader("From a Thu Sep 10 09:16:46 2009\r\nSubject: crlf\r\n\r\nbody\r\n\r\n\r\nFrom b Thu Sep 10 09:16:46 2009\r\nSubject: last\r\n\r\nend"))
+	raw, err := r.NextRaw()
+	if err != nil {
+		t.Fatalf("Expected nil but got %v", err)
+	}
+	b, _ := ioutil.ReadAll(raw)
+	expected := "Subject: crlf\r\n\r\nbody\r\n\r\n"
+	if string(b) != expected {
+		t.Errorf(`Expected "%q" but got "%q"`, expected, string(b))
+	}
+	raw, _ = r.NextRaw()
+	b, _ = ioutil.ReadAll(raw)
+	expected = "Subject: last\r\n\r\nend"
+	if string(b) != expected {
+		t.Errorf(`Expected "%q" but got "%q"`, expected, string(b))
+	}
+	if _, err := r.NextRaw(); err != io.EOF {
+		t.Errorf(`Expected "%v" but got "%v"`, io.EOF, err)
+	}
+}
+
+func TestReaderEmpty(t *testing.T) {
+	r := NewReader(strings.NewReader(""))
+	msg, err := r.Next()
+	if err != io.EOF {
+		t.Errorf(`Expected "%v" but got "%v"`, io.EOF, err)
+	}
+	if msg != nil {
+		t.Errorf("Expected nil but got %v", msg)
+	}
+	if r.Offset() != -1 {
+		t.Errorf(`Expected -1 but got %v`, r.Offset())
+	}
+}
+
+func TestReaderMalformedMessage(t *testing.T) {
+	r := NewReader(strings.NewReader("From a Thu Sep 10 09:16:46 2009\nnot a header\n\nFrom b Thu Sep 10 09:16:46 2009\nSubject: ok\n\nbody\n"))
+	_, err := r.Next()
+	expected := "mbox: message at offset 0: "
+	if err == nil || !strings.HasPrefix(err.Error(), expected) {
+		t.Errorf(`Expected "%v" but got "%v"`, expected, err)
+	}
+	msg, err := r.Next()
+	if err != nil {
+		t.Fatalf("Expected nil but got %v", err)
+	}
+	if msg.Header.Get("Subject") != "ok" {
+		t.Errorf(`Expected "ok" but got "%v"`, msg.Header.Get("Subject"))
+	}
+}
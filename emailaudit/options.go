@@ -0,0 +1,94 @@
+package emailaudit
+
+import (
+	"context"
+	"time"
+)
+
+// Option configures Service in New
+type Option interface {
+	apply(s *Service)
+}
+
+type optionFunc func(s *Service)
+
+func (f optionFunc) apply(s *Service) {
+	f(s)
+}
+
+// Clock tells the current time and waits for durations.
+// Tests can replace the clock of Service with WithClock.
+type Clock interface {
+	Now() time.Time
+	After(d time.Duration) <-chan time.Time
+}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
+
+func (systemClock) After(d time.Duration) <-chan time.Time {
+	return time.After(d)
+}
+
+// WithUserAgent appends userAgent to User-Agent header of requests
+func WithUserAgent(userAgent string) Option {
+	return optionFunc(func(s *Service) {
+		s.userAgent = userAgent
+	})
+}
+
+// WithBaseURL sets the endpoint of Email Audit API (default DefaultBaseURL)
+func WithBaseURL(baseURL string) Option {
+	return optionFunc(func(s *Service) {
+		s.baseURL = baseURL
+	})
+}
+
+// WithRetryPolicy retries failed requests according to policy. See DefaultRetryPolicy.
+func WithRetryPolicy(policy *RetryPolicy) Option {
+	return optionFunc(func(s *Service) {
+		if policy == nil {
+			s.retryPolicy = nil
+			return
+		}
+		p := *policy
+		s.retryPolicy = &p
+	})
+}
+
+// WithRateLimiter paces requests per domain with limiter, which can be shared between services
+func WithRateLimiter(limiter *RateLimiter) Option {
+	return optionFunc(func(s *Service) {
+		s.rateLimiter = limiter
+	})
+}
+
+// WithLogger logs retries to logger
+func WithLogger(logger Logger) Option {
+	return optionFunc(func(s *Service) {
+		s.logger = logger
+	})
+}
+
+// WithClock replaces the clock used for validation, retries, polling and purging
+func WithClock(clock Clock) Option {
+	return optionFunc(func(s *Service) {
+		if clock == nil {
+			clock = systemClock{}
+		}
+		s.clock = clock
+	})
+}
+
+// sleep waits for d on the clock of the service or until ctx is done
+func (s *Service) sleep(ctx context.Context, d time.Duration) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-s.clock.After(d):
+		return nil
+	}
+}
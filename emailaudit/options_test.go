@@ -0,0 +1,164 @@
+package emailaudit
+
+import (
+	"context"
+	"net/http"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	gock "gopkg.in/h2non/gock.v1"
+)
+
+type fakeClock struct {
+	now   time.Time
+	mu    sync.Mutex
+	waits []time.Duration
+}
+
+func (c *fakeClock) Now() time.Time {
+	return c.now
+}
+
+func (c *fakeClock) After(d time.Duration) <-chan time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.waits = append(c.waits, d)
+	ch := make(chan time.Time, 1)
+	ch <- c.now.Add(d)
+	return ch
+}
+
+func TestNewServiceOptions(t *testing.T) {
+	policy := DefaultRetryPolicy()
+	limiter := NewRateLimiter(1, 1)
+	logger := &testLogger{}
+	clock := &fakeClock{}
+	svc, err := New(&http.Client{},
+		WithUserAgent("foo"),
+		WithBaseURL("http://localhost:8080/audit"),
+		WithRetryPolicy(policy),
+		WithRateLimiter(limiter),
+		WithLogger(logger),
+		WithClock(clock),
+	)
+	if err != nil {
+		t.Fatalf("Expected nil but got %v", err)
+	}
+	policy.MaxAttempts = 10
+	for _, test := range []struct {
+		actual   interface{}
+		expected interface{}
+	}{
+		{svc.userAgent, "foo"},
+		{svc.baseURL, "http://localhost:8080/audit"},
+		{svc.retryPolicy.MaxAttempts, 4},
+		{svc.retryPolicy != policy, true},
+		{svc.rateLimiter, limiter},
+		{svc.logger, Logger(logger)},
+		{svc.clock, Clock(clock)},
+		{svc.endpoint(mailMonitorPath), "http://localhost:8080/audit/mail/monitor"},
+	} {
+		if test.actual != test.expected {
+			t.Errorf(`Expected "%v" but got "%v"`, test.expected, test.actual)
+		}
+	}
+	if svc.MailMonitor.s != svc || svc.Export.s != svc || svc.PublicKey.s != svc || svc.AccountInfo.s != svc {
+		t.Errorf("Expected sub-services of %v", svc)
+	}
+	svc, _ = New(&http.Client{}, WithRetryPolicy(nil), WithClock(nil))
+	if svc.retryPolicy != nil {
+		t.Errorf("Expected nil but got %v", svc.retryPolicy)
+	}
+	if _, ok := svc.clock.(systemClock); !ok {
+		t.Errorf("Expected systemClock but got %T", svc.clock)
+	}
+}
+
+func TestWithClockValidate(t *testing.T) {
+	defer gock.Off()
+	gock.New("https://apps-apis.google.com").
+		Post("/a/feeds/compliance/audit/mail/monitor/example.com/abhishek").
+		Reply(200).
+		XML(monitorXML)
+
+	clock := &fakeClock{now: time.Date(2016, time.October, 1, 0, 0, 0, 0, time.UTC)}
+	svc := newTestService(WithClock(clock))
+	endDate := time.Date(2016, time.October, 30, 14, 59, 0, 0, time.UTC)
+	m, err := svc.MailMonitor.Update("example.com", "abhishek", "namrata", endDate, MailMonitorLevels{Chat: HeaderOnlyLevel})
+	if err != nil {
+		t.Errorf("Expected nil but got %v", err)
+	}
+	_TestMonitor(m, t)
+}
+
+func TestWithClockPurge(t *testing.T) {
+	defer gock.Off()
+	gock.New("https://apps-apis.google.com").
+		Get("/a/feeds/compliance/audit/mail/export/example.com").
+		Reply(200).
+		XML(purgeExportsXML)
+	gock.New("https://apps-apis.google.com").
+		Delete("/a/feeds/compliance/audit/mail/export/example.com/abhishek/2").
+		Reply(200)
+
+	clock := &fakeClock{now: time.Date(2009, time.September, 11, 12, 0, 0, 0, time.UTC)}
+	results, err := newTestService(WithClock(clock)).Export.Purge(context.Background(), "example.com", time.Hour)
+	if err != nil {
+		t.Errorf("Expected nil but got %v", err)
+	}
+	if len(results) != 1 || results[0].Export.RequestID != "2" || results[0].Err != nil {
+		t.Errorf("Expected request 2 to be purged but got %v", results)
+	}
+}
+
+func TestWithClockWaitForCompletion(t *testing.T) {
+	defer gock.Off()
+	gock.New("https://apps-apis.google.com").
+		Get("/a/feeds/compliance/audit/mail/export/example.com/abhishek/53156").
+		Times(2).
+		Reply(200).
+		XML(exportXML)
+	gock.New("https://apps-apis.google.com").
+		Get("/a/feeds/compliance/audit/mail/export/example.com/abhishek/53156").
+		Reply(200).
+		XML(completedExportXML)
+
+	clock := &fakeClock{}
+	e, err := newTestService(WithClock(clock)).Export.WaitForCompletion(context.Background(), "example.com", "abhishek", "53156", WaitOptions{})
+	if err != nil {
+		t.Errorf("Expected nil but got %v", err)
+	}
+	if e.Status != CompletedStatus {
+		t.Errorf(`Expected "%v" but got "%v"`, CompletedStatus, e.Status)
+	}
+	expected := []time.Duration{30 * time.Second, time.Minute}
+	if !reflect.DeepEqual(clock.waits, expected) {
+		t.Errorf(`Expected "%v" but got "%v"`, expected, clock.waits)
+	}
+}
+
+func TestWithClockRetry(t *testing.T) {
+	defer gock.Off()
+	gock.New("https://apps-apis.google.com").
+		Get("/a/feeds/compliance/audit/mail/monitor/example.com/abhishek").
+		Reply(503).
+		SetHeader("Retry-After", "Sun, 30 Oct 2016 15:00:00 GMT")
+	gock.New("https://apps-apis.google.com").
+		Get("/a/feeds/compliance/audit/mail/monitor/example.com/abhishek").
+		Reply(200).
+		XML(monitorsXML)
+
+	clock := &fakeClock{now: time.Date(2016, time.October, 30, 14, 59, 0, 0, time.UTC)}
+	svc := newTestService(WithRetryPolicy(DefaultRetryPolicy()), WithClock(clock))
+	m, err := svc.MailMonitor.List("example.com", "abhishek")
+	if err != nil {
+		t.Errorf("Expected nil but got %v", err)
+	}
+	_TestMonitors(m, t)
+	expected := []time.Duration{time.Minute}
+	if !reflect.DeepEqual(clock.waits, expected) {
+		t.Errorf(`Expected "%v" but got "%v"`, expected, clock.waits)
+	}
+}
@@ -0,0 +1,62 @@
+package emailaudit
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"github.com/ProtonMail/go-crypto/openpgp/packet"
+)
+
+const publicKeyPath = "publickey"
+
+// PublicKeyService PublicKeyService
+type PublicKeyService struct {
+	s *Service
+}
+
+// NewPublicKeyService returns new PublicKeyService
+func NewPublicKeyService(s *Service) *PublicKeyService {
+	rs := &PublicKeyService{s: s}
+	return rs
+}
+
+// Upload uploads an ASCII armored OpenPGP RSA public key used to encrypt mailbox exports
+// - https://developers.google.com/admin-sdk/email-audit/#uploading_a_public_key
+func (svc *PublicKeyService) Upload(domainName string, armoredKey string) error {
+	return svc.UploadContext(context.Background(), domainName, armoredKey)
+}
+
+// UploadContext is Upload with a context
+func (svc *PublicKeyService) UploadContext(ctx context.Context, domainName string, armoredKey string) error {
+	if err := validatePublicKey(armoredKey); err != nil {
+		return err
+	}
+	m := monitorWriteProperties{}
+	m.addProperty("publicKey", base64.StdEncoding.EncodeToString([]byte(armoredKey)))
+	url := fmt.Sprintf("%v/%v", svc.s.endpoint(publicKeyPath), domainName)
+	_, err := svc.s.do(ctx, domainName, "POST", url, m.toXML())
+	return err
+}
+
+func validatePublicKey(armoredKey string) error {
+	entities, err := openpgp.ReadArmoredKeyRing(strings.NewReader(armoredKey))
+	if err != nil {
+		return fmt.Errorf("invalid public key: %v", err)
+	}
+	if len(entities) != 1 {
+		return fmt.Errorf("invalid public key: expected 1 key but got %v", len(entities))
+	}
+	e := entities[0]
+	if e.PrivateKey != nil {
+		return errors.New("invalid public key: private key is given")
+	}
+	switch e.PrimaryKey.PubKeyAlgo {
+	case packet.PubKeyAlgoRSA, packet.PubKeyAlgoRSAEncryptOnly:
+		return nil
+	}
+	return errors.New("invalid public key: not an RSA key")
+}
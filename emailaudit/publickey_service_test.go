@@ -0,0 +1,88 @@
+package emailaudit
+
+import (
+	"bytes"
+	"encoding/base64"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"github.com/ProtonMail/go-crypto/openpgp/armor"
+
+	gock "gopkg.in/h2non/gock.v1"
+)
+
+func armoredTestKey(private bool) string {
+	e, _ := openpgp.NewEntity("Admin", "", "admin@example.com", nil)
+	var buf bytes.Buffer
+	blockType := openpgp.PublicKeyType
+	if private {
+		blockType = openpgp.PrivateKeyType
+	}
+	w, _ := armor.Encode(&buf, blockType, nil)
+	if private {
+		e.SerializePrivate(w, nil)
+	} else {
+		e.Serialize(w)
+	}
+	w.Close()
+	return buf.String()
+}
+
+func TestPublicKeyServiceUpload(t *testing.T) {
+	defer gock.Off()
+	key := armoredTestKey(false)
+	var body string
+	gock.New("https://apps-apis.google.com").
+		Post("/a/feeds/compliance/audit/publickey/example.com").
+		MatchType("application/atom\\+xml").
+		MatchHeader("Authorization", "Bearer test").
+		MatchHeader("User-Agent", "google-api-go-client/0.5").
+		AddMatcher(func(req *http.Request, ereq *gock.Request) (bool, error) {
+			b, err := ioutil.ReadAll(req.Body)
+			body = string(b)
+			return true, err
+		}).
+		Reply(201)
+
+	err := newTestService().PublicKey.Upload("example.com", key)
+	if err != nil {
+		t.Errorf("Expected nil but got %v", err)
+	}
+	expected := `<apps:property name="publicKey" value="` + base64.StdEncoding.EncodeToString([]byte(key)) + `"></apps:property>`
+	if !strings.Contains(body, expected) {
+		t.Errorf(`Expected "%v" to contain "%v"`, body, expected)
+	}
+}
+
+func TestPublicKeyServiceUploadInvalidKey(t *testing.T) {
+	svc := newTestService()
+	for _, test := range []struct {
+		key      string
+		expected string
+	}{
+		{"foo", "invalid public key: openpgp: invalid argument: no armored data found"},
+		{armoredTestKey(true), "invalid public key: private key is given"},
+	} {
+		err := svc.PublicKey.Upload("example.com", test.key)
+		if err == nil || err.Error() != test.expected {
+			t.Errorf(`Expected "%v" but got "%v"`, test.expected, err)
+		}
+	}
+}
+
+func TestPublicKeyServiceUploadHTTPErrorResponse(t *testing.T) {
+	defer gock.Off()
+	gock.New("https://apps-apis.google.com").
+		Post("/a/feeds/compliance/audit/publickey/example.com").
+		Reply(400).
+		BodyString("Omg")
+
+	err := newTestService().PublicKey.Upload("example.com", armoredTestKey(false))
+	expected := "400 Bad Request: Omg"
+	if err == nil || err.Error() != expected {
+		t.Errorf(`Expected "%v" but got "%v"`, expected, err)
+	}
+}
@@ -0,0 +1,441 @@
+package emailaudit
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+const queryDateFormat = "2006/01/02"
+
+var (
+	queryDurationPattern = regexp.MustCompile(`^[0-9]+[dmy]$`)
+	querySizePattern     = regexp.MustCompile(`^[0-9]+[kKmM]?$`)
+	queryDatePattern     = regexp.MustCompile(`^[0-9]{4}[/-][0-9]{1,2}[/-][0-9]{1,2}$`)
+)
+
+// queryOperators maps search operators to their allowed values (nil allows any value)
+var queryOperators = map[string][]string{
+	"from":        nil,
+	"to":          nil,
+	"cc":          nil,
+	"bcc":         nil,
+	"subject":     nil,
+	"label":       nil,
+	"list":        nil,
+	"filename":    nil,
+	"deliveredto": nil,
+	"rfc822msgid": nil,
+	"after":       nil,
+	"before":      nil,
+	"older":       nil,
+	"newer":       nil,
+	"older_than":  nil,
+	"newer_than":  nil,
+	"size":        nil,
+	"larger":      nil,
+	"smaller":     nil,
+	"has":         {"attachment", "drive", "document", "spreadsheet", "presentation", "youtube", "userlabels", "nouserlabels"},
+	"in":          {"anywhere", "inbox", "trash", "spam", "sent", "drafts", "chats", "chat"},
+	"is":          {"important", "starred", "unread", "read", "chat", "muted", "snoozed"},
+}
+
+// Query builds a Gmail search query used as searchQuery of mailbox export requests.
+// Terms are joined with AND.
+// Gmail has no escape for double quotes, so values containing them fail Validate.
+type Query struct {
+	terms []string
+}
+
+// QueryError QueryError
+type QueryError struct {
+	Query  string
+	Pos    int
+	Reason string
+}
+
+func (e *QueryError) Error() string {
+	return fmt.Sprintf("invalid search query %q at %d: %v", e.Query, e.Pos, e.Reason)
+}
+
+// NewQuery returns new Query
+func NewQuery() *Query {
+	return &Query{}
+}
+
+// ParseQuery validates a raw search query string and returns it as a Query
+func ParseQuery(s string) (*Query, error) {
+	s = strings.TrimSpace(s)
+	if err := validateQuery(s); err != nil {
+		return nil, err
+	}
+	q := &Query{}
+	if s != "" {
+		q.terms = append(q.terms, s)
+	}
+	return q, nil
+}
+
+// From matches messages sent by address
+func (q *Query) From(address string) *Query {
+	return q.add("from", address)
+}
+
+// To matches messages sent to address
+func (q *Query) To(address string) *Query {
+	return q.add("to", address)
+}
+
+// Subject matches messages whose subject contains text
+func (q *Query) Subject(text string) *Query {
+	return q.add("subject", text)
+}
+
+// Label matches messages with the label
+func (q *Query) Label(label string) *Query {
+	return q.add("label", label)
+}
+
+// HasAttachment matches messages with attachments
+func (q *Query) HasAttachment() *Query {
+	return q.add("has", "attachment")
+}
+
+// Before matches messages sent before the date
+func (q *Query) Before(date time.Time) *Query {
+	return q.add("before", date.Format(queryDateFormat))
+}
+
+// After matches messages sent after the date
+func (q *Query) After(date time.Time) *Query {
+	return q.add("after", date.Format(queryDateFormat))
+}
+
+// Text matches messages containing text
+func (q *Query) Text(text string) *Query {
+	if text == "OR" || text == "AND" {
+		// quote boolean operators to search them as words
+		q.terms = append(q.terms, `"`+text+`"`)
+		return q
+	}
+	q.terms = append(q.terms, quoteQueryValue(text))
+	return q
+}
+
+// Or matches messages matching any of queries
+func (q *Query) Or(queries ...*Query) *Query {
+	var parts []string
+	for _, query := range queries {
+		if s := query.group(); s != "" {
+			parts = append(parts, s)
+		}
+	}
+	if len(parts) > 0 {
+		q.terms = append(q.terms, "("+strings.Join(parts, " OR ")+")")
+	}
+	return q
+}
+
+// And matches messages matching all of queries
+func (q *Query) And(queries ...*Query) *Query {
+	for _, query := range queries {
+		if s := query.group(); s != "" {
+			q.terms = append(q.terms, s)
+		}
+	}
+	return q
+}
+
+// Not matches messages not matching query
+func (q *Query) Not(query *Query) *Query {
+	s := query.String()
+	if s == "" {
+		return q
+	}
+	q.terms = append(q.terms, "-("+s+")")
+	return q
+}
+
+// String returns the search query string
+func (q *Query) String() string {
+	return strings.Join(q.terms, " ")
+}
+
+// Validate returns QueryError if the search query is invalid
+func (q *Query) Validate() error {
+	return validateQuery(q.String())
+}
+
+func (q *Query) add(operator string, value string) *Query {
+	q.terms = append(q.terms, operator+":"+quoteQueryValue(value))
+	return q
+}
+
+func (q *Query) group() string {
+	if len(q.terms) == 1 && isAtomicQueryTerm(q.terms[0]) {
+		return q.terms[0]
+	}
+	if len(q.terms) == 0 {
+		return ""
+	}
+	return "(" + q.String() + ")"
+}
+
+// isAtomicQueryTerm reports whether term has no spaces outside quotes and parentheses,
+// unlike raw queries of ParseQuery such as "from:a to:b"
+func isAtomicQueryTerm(term string) bool {
+	depth := 0
+	quoted := false
+	for _, c := range term {
+		switch {
+		case c == '"':
+			quoted = !quoted
+		case quoted:
+		case c == '(' || c == '{':
+			depth++
+		case c == ')' || c == '}':
+			depth--
+		case (c == ' ' || c == '\t') && depth == 0:
+			return false
+		}
+	}
+	return true
+}
+
+func quoteQueryValue(value string) string {
+	if strings.ContainsAny(value, " \t(){}\"") {
+		return `"` + value + `"`
+	}
+	return value
+}
+
+type queryToken int
+
+const (
+	queryStart queryToken = iota
+	queryOpen
+	queryClose
+	queryTerm
+	queryBool
+)
+
+func validateQuery(s string) error {
+	fail := func(pos int, format string, args ...interface{}) error {
+		return &QueryError{Query: s, Pos: pos, Reason: fmt.Sprintf(format, args...)}
+	}
+	var stack []byte
+	var stackPos []int
+	prev := queryStart
+	i := 0
+	for i < len(s) {
+		c := s[i]
+		switch {
+		case c == ' ' || c == '\t':
+			i++
+			continue
+		case c == '(' || c == '{':
+			stack = append(stack, c)
+			stackPos = append(stackPos, i)
+			prev = queryOpen
+			i++
+			continue
+		case c == ')' || c == '}':
+			open := byte('(')
+			if c == '}' {
+				open = '{'
+			}
+			if len(stack) == 0 || stack[len(stack)-1] != open {
+				return fail(i, "unexpected %q", c)
+			}
+			if prev == queryOpen || prev == queryBool {
+				return fail(i, "empty group")
+			}
+			stack = stack[:len(stack)-1]
+			stackPos = stackPos[:len(stackPos)-1]
+			prev = queryClose
+			i++
+			continue
+		}
+		start := i
+		if c == '-' {
+			i++
+			if i == len(s) || s[i] == ' ' || s[i] == '\t' || s[i] == ')' || s[i] == '}' {
+				return fail(start, "dangling -")
+			}
+			if s[i] == '(' || s[i] == '{' {
+				continue
+			}
+		}
+		word, end, err := readQueryWord(s, i)
+		if err != nil {
+			return fail(end, "%v", err)
+		}
+		i = end
+		if word == "OR" || word == "AND" {
+			if start != i-len(word) || prev == queryStart || prev == queryOpen || prev == queryBool {
+				return fail(start, "unexpected %v", word)
+			}
+			prev = queryBool
+			continue
+		}
+		if err := validateQueryTerm(word); err != nil {
+			return fail(start, "%v", err)
+		}
+		prev = queryTerm
+	}
+	if len(stack) > 0 {
+		return fail(stackPos[len(stackPos)-1], "unclosed %q", stack[len(stack)-1])
+	}
+	if prev == queryBool {
+		return fail(len(s), "missing term after boolean operator")
+	}
+	return nil
+}
+
+// readQueryWord reads a term starting at i, including a quoted or parenthesized operator value.
+// On error, the returned position is where the error is.
+func readQueryWord(s string, i int) (string, int, error) {
+	start := i
+	if s[i] == '"' {
+		end := strings.IndexByte(s[i+1:], '"')
+		if end < 0 {
+			return "", start, fmt.Errorf("unclosed quote")
+		}
+		return closeQueryWord(s, start, i+end+2)
+	}
+	for !isQueryWordEnd(s, i) {
+		if s[i] == '"' {
+			return "", i, fmt.Errorf("unexpected quote")
+		}
+		if s[i] == ':' && i+1 < len(s) && (s[i+1] == '"' || s[i+1] == '(') {
+			closing := byte('"')
+			if s[i+1] == '(' {
+				closing = ')'
+			}
+			end := strings.IndexByte(s[i+2:], closing)
+			if end < 0 {
+				return "", start, fmt.Errorf("unclosed value of %v", s[start:i+1])
+			}
+			return closeQueryWord(s, start, i+end+3)
+		}
+		i++
+	}
+	return s[start:i], i, nil
+}
+
+// closeQueryWord returns the word ending with a closing quote or parenthesis at end-1,
+// which must be followed by a separator, e.g. not by the rest of a value with embedded quotes
+func closeQueryWord(s string, start int, end int) (string, int, error) {
+	if !isQueryWordEnd(s, end) {
+		return "", end, fmt.Errorf("unexpected %q after closing %q", s[end], s[end-1])
+	}
+	return s[start:end], end, nil
+}
+
+func isQueryWordEnd(s string, i int) bool {
+	return i == len(s) || strings.ContainsRune(" \t(){}", rune(s[i]))
+}
+
+func validateQueryTerm(word string) error {
+	if strings.HasPrefix(word, `"`) {
+		return nil
+	}
+	idx := strings.IndexByte(word, ':')
+	if idx < 0 {
+		return nil
+	}
+	operator := strings.ToLower(word[:idx])
+	value := strings.Trim(word[idx+1:], `"()`)
+	values, ok := queryOperators[operator]
+	if !ok {
+		// Only likely typos of operators are errors, words like 10:30 or http://example.com are free text
+		if suggestion := suggestQueryOperator(operator); suggestion != "" && isQueryOperatorName(operator) {
+			return fmt.Errorf("unknown operator %q (did you mean %q?)", operator, suggestion)
+		}
+		return nil
+	}
+	if strings.TrimSpace(value) == "" {
+		return fmt.Errorf("missing value of %v:", operator)
+	}
+	switch operator {
+	case "after", "before", "older", "newer":
+		if !queryDatePattern.MatchString(value) {
+			return fmt.Errorf("invalid date %q of %v: (expected YYYY/MM/DD)", value, operator)
+		}
+	case "older_than", "newer_than":
+		if !queryDurationPattern.MatchString(value) {
+			return fmt.Errorf("invalid duration %q of %v: (expected e.g. 7d, 2m or 1y)", value, operator)
+		}
+	case "size", "larger", "smaller":
+		if !querySizePattern.MatchString(value) {
+			return fmt.Errorf("invalid size %q of %v:", value, operator)
+		}
+	}
+	if values == nil {
+		return nil
+	}
+	for _, v := range values {
+		if strings.ToLower(value) == v {
+			return nil
+		}
+	}
+	return fmt.Errorf("invalid value %q of %v: (expected one of %v)", value, operator, strings.Join(values, ", "))
+}
+
+func isQueryOperatorName(s string) bool {
+	for _, c := range s {
+		if !(c >= 'a' && c <= 'z' || c == '_') {
+			return false
+		}
+	}
+	return s != ""
+}
+
+func suggestQueryOperator(operator string) string {
+	maxDistance := len(operator) / 2
+	if maxDistance > 2 {
+		maxDistance = 2
+	}
+	best := ""
+	for op := range queryOperators {
+		d := levenshtein(operator, op)
+		if d > maxDistance {
+			continue
+		}
+		if best == "" || d < maxDistance || op < best {
+			best, maxDistance = op, d
+		}
+	}
+	return best
+}
+
+func levenshtein(a string, b string) int {
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min3(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(b)]
+}
+
+func min3(a int, b int, c int) int {
+	if b < a {
+		a = b
+	}
+	if c < a {
+		a = c
+	}
+	return a
+}
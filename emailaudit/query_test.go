@@ -0,0 +1,124 @@
+package emailaudit
+
+import (
+	"testing"
+	"time"
+)
+
+func TestQueryString(t *testing.T) {
+	date := time.Date(2016, time.October, 30, 0, 0, 0, 0, time.UTC)
+	for _, test := range []struct {
+		actual   interface{}
+		expected interface{}
+	}{
+		{NewQuery().String(), ""},
+		{NewQuery().From("abhishek@example.com").To("namrata@example.com").String(),
+			"from:abhishek@example.com to:namrata@example.com"},
+		{NewQuery().Subject("quarterly report").HasAttachment().Label("legal").String(),
+			`subject:"quarterly report" has:attachment label:legal`},
+		{NewQuery().After(date).Before(date.AddDate(0, 1, 0)).String(),
+			"after:2016/10/30 before:2016/11/30"},
+		{NewQuery().Or(NewQuery().From("a@example.com"), NewQuery().From("b@example.com").HasAttachment()).String(),
+			"(from:a@example.com OR (from:b@example.com has:attachment))"},
+		{NewQuery().Text("merger").Not(NewQuery().Label("spam")).String(),
+			"merger -(label:spam)"},
+		{NewQuery().And(NewQuery().From("a@example.com"), NewQuery()).String(), "from:a@example.com"},
+		{NewQuery().Or(mustParseQuery("from:a to:b"), mustParseQuery("subject:c")).String(), "((from:a to:b) OR subject:c)"},
+		{NewQuery().Or(mustParseQuery(`subject:"a b"`), mustParseQuery("(from:a to:b)")).String(), `(subject:"a b" OR (from:a to:b))`},
+		{NewQuery().Text("OR").Text("AND").Text("or").String(), `"OR" "AND" or`},
+	} {
+		if test.actual != test.expected {
+			t.Errorf(`Expected "%v" but got "%v"`, test.expected, test.actual)
+		}
+	}
+}
+
+func mustParseQuery(s string) *Query {
+	q, err := ParseQuery(s)
+	if err != nil {
+		panic(err)
+	}
+	return q
+}
+
+func TestQueryValidate(t *testing.T) {
+	q := NewQuery().From("a@example.com").Subject("x (y)").Or(NewQuery().Label("a"), NewQuery().Label("b")).Text("OR")
+	if err := q.Validate(); err != nil {
+		t.Errorf("Expected nil but got %v", err)
+	}
+	for _, q := range []*Query{
+		NewQuery().Subject(`say "hi" now`),
+		NewQuery().Subject(`"hi"`),
+		NewQuery().Text(`a"b`),
+	} {
+		if _, ok := q.Validate().(*QueryError); !ok {
+			t.Errorf("Expected *QueryError but got %v for %v", q.Validate(), q)
+		}
+	}
+}
+
+func TestParseQuery(t *testing.T) {
+	for _, s := range []string{
+		"",
+		"from:abhishek@example.com",
+		"  in:chats  ",
+		`subject:"quarterly report" has:attachment`,
+		"subject:(report dinner) -label:spam",
+		"from:a OR from:b",
+		"{from:a from:b} after:2016/10/30 before:2016-11-30",
+		"(from:a OR to:b) AND -(has:attachment larger:10M) older_than:1y",
+		`"exact phrase" is:starred`,
+		"meeting 10:30",
+		"http://example.com/x from:a",
+		"foo:bar",
+	} {
+		q, err := ParseQuery(s)
+		if err != nil {
+			t.Errorf("Expected nil but got %v", err)
+			continue
+		}
+		if q.Validate() != nil {
+			t.Errorf("Expected nil but got %v", q.Validate())
+		}
+	}
+}
+
+func TestParseQueryError(t *testing.T) {
+	for _, test := range []struct {
+		query    string
+		expected string
+	}{
+		{"form:abhishek@example.com", `invalid search query "form:abhishek@example.com" at 0: unknown operator "form" (did you mean "from"?)`},
+		{"from:a fro:bar", `invalid search query "from:a fro:bar" at 7: unknown operator "fro" (did you mean "from"?)`},
+		{"from:", `invalid search query "from:" at 0: missing value of from:`},
+		{"has:attachement", `invalid search query "has:attachement" at 0: invalid value "attachement" of has: (expected one of attachment, drive, document, spreadsheet, presentation, youtube, userlabels, nouserlabels)`},
+		{"before:yesterday", `invalid search query "before:yesterday" at 0: invalid date "yesterday" of before: (expected YYYY/MM/DD)`},
+		{"newer_than:7w", `invalid search query "newer_than:7w" at 0: invalid duration "7w" of newer_than: (expected e.g. 7d, 2m or 1y)`},
+		{"larger:big", `invalid search query "larger:big" at 0: invalid size "big" of larger:`},
+		{"(from:a", `invalid search query "(from:a" at 0: unclosed '('`},
+		{"from:a)", `invalid search query "from:a)" at 6: unexpected ')'`},
+		{"{from:a)", `invalid search query "{from:a)" at 7: unexpected ')'`},
+		{"()", `invalid search query "()" at 1: empty group`},
+		{"OR from:a", `invalid search query "OR from:a" at 0: unexpected OR`},
+		{"from:a OR", `invalid search query "from:a OR" at 9: missing term after boolean operator`},
+		{"from:a OR AND from:b", `invalid search query "from:a OR AND from:b" at 10: unexpected AND`},
+		{"from:a - to:b", `invalid search query "from:a - to:b" at 7: dangling -`},
+		{`"unclosed`, `invalid search query "\"unclosed" at 0: unclosed quote`},
+		{`subject:"unclosed`, `invalid search query "subject:\"unclosed" at 0: unclosed value of subject:`},
+		{`subject:"say "hi" now"`, `invalid search query "subject:\"say \"hi\" now\"" at 14: unexpected 'h' after closing '"'`},
+		{`say"hi`, `invalid search query "say\"hi" at 3: unexpected quote`},
+		{`"a"b`, `invalid search query "\"a\"b" at 3: unexpected 'b' after closing '"'`},
+		{"subject:(a b)c", `invalid search query "subject:(a b)c" at 13: unexpected 'c' after closing ')'`},
+	} {
+		q, err := ParseQuery(test.query)
+		if err == nil || err.Error() != test.expected {
+			t.Errorf(`Expected "%v" but got "%v"`, test.expected, err)
+		}
+		if q != nil {
+			t.Errorf("Expected nil but got %v", q)
+		}
+		if _, ok := err.(*QueryError); !ok {
+			t.Errorf("Expected *QueryError but got %T", err)
+		}
+	}
+}
@@ -0,0 +1,150 @@
+package emailaudit
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrRateLimited is returned when a request would wait for the rate limiter
+// longer than MaxWait or the deadline of its context
+var ErrRateLimited = errors.New("rate limit exceeded")
+
+// RateLimiter paces requests with a token bucket per domain
+type RateLimiter struct {
+	// Rate is the number of requests per second allowed per domain (0 allows every request)
+	Rate float64
+	// Burst is the number of requests allowed at once per domain
+	Burst int
+	// MaxWait rejects requests which would wait longer (0 waits as long as the context allows)
+	MaxWait time.Duration
+
+	mu      sync.Mutex
+	buckets map[string]*tokenBucket
+}
+
+// RateLimiterStats is a snapshot of the rate limiter of a domain
+type RateLimiterStats struct {
+	// Wait is the time a new request would wait now
+	Wait     time.Duration
+	Waiting  int
+	Allowed  int64
+	Rejected int64
+}
+
+type tokenBucket struct {
+	tokens   float64
+	last     time.Time
+	waiting  int
+	allowed  int64
+	rejected int64
+}
+
+// NewRateLimiter returns new RateLimiter allowing rate requests per second
+// with bursts of burst requests per domain
+func NewRateLimiter(rate float64, burst int) *RateLimiter {
+	return &RateLimiter{Rate: rate, Burst: burst}
+}
+
+// Wait blocks until a request to the domain is allowed or ctx is done
+func (l *RateLimiter) Wait(ctx context.Context, domainName string) error {
+	now := time.Now()
+	l.mu.Lock()
+	b := l.bucket(domainName, now)
+	if l.Rate <= 0 {
+		b.allowed++
+		l.mu.Unlock()
+		return nil
+	}
+	b.tokens--
+	delay := l.delay(b)
+	if deadline, ok := ctx.Deadline(); (ok && now.Add(delay).After(deadline)) || (l.MaxWait > 0 && delay > l.MaxWait) {
+		b.tokens++
+		b.rejected++
+		l.mu.Unlock()
+		return ErrRateLimited
+	}
+	if delay <= 0 {
+		b.allowed++
+		l.mu.Unlock()
+		return nil
+	}
+	b.waiting++
+	l.mu.Unlock()
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		l.mu.Lock()
+		b.waiting--
+		b.tokens++
+		b.rejected++
+		l.mu.Unlock()
+		return ctx.Err()
+	case <-timer.C:
+		l.mu.Lock()
+		b.waiting--
+		b.allowed++
+		l.mu.Unlock()
+		return nil
+	}
+}
+
+// Stats returns a snapshot of every domain requested so far
+func (l *RateLimiter) Stats() map[string]RateLimiterStats {
+	now := time.Now()
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	stats := map[string]RateLimiterStats{}
+	for domainName := range l.buckets {
+		b := l.bucket(domainName, now)
+		wait := time.Duration(0)
+		if b.tokens < 1 && l.Rate > 0 {
+			wait = time.Duration((1 - b.tokens) / l.Rate * float64(time.Second))
+		}
+		stats[domainName] = RateLimiterStats{
+			Wait:     wait,
+			Waiting:  b.waiting,
+			Allowed:  b.allowed,
+			Rejected: b.rejected,
+		}
+	}
+	return stats
+}
+
+// bucket returns the bucket of the domain refilled until now. l.mu must be held.
+func (l *RateLimiter) bucket(domainName string, now time.Time) *tokenBucket {
+	if l.buckets == nil {
+		l.buckets = map[string]*tokenBucket{}
+	}
+	b, ok := l.buckets[domainName]
+	if !ok {
+		b = &tokenBucket{tokens: float64(l.burst()), last: now}
+		l.buckets[domainName] = b
+	}
+	if now.After(b.last) && l.Rate > 0 {
+		b.tokens += now.Sub(b.last).Seconds() * l.Rate
+		if b.tokens > float64(l.burst()) {
+			b.tokens = float64(l.burst())
+		}
+		b.last = now
+	}
+	return b
+}
+
+// delay returns the time until the tokens of b become non-negative
+func (l *RateLimiter) delay(b *tokenBucket) time.Duration {
+	if b.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-b.tokens / l.Rate * float64(time.Second))
+}
+
+func (l *RateLimiter) burst() int {
+	if l.Burst < 1 {
+		return 1
+	}
+	return l.Burst
+}
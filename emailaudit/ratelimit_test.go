@@ -0,0 +1,117 @@
+package emailaudit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	gock "gopkg.in/h2non/gock.v1"
+)
+
+func TestRateLimiterWait(t *testing.T) {
+	l := NewRateLimiter(50, 2)
+	ctx := context.Background()
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		if err := l.Wait(ctx, "example.com"); err != nil {
+			t.Errorf("Expected nil but got %v", err)
+		}
+	}
+	if elapsed := time.Since(start); elapsed < 15*time.Millisecond {
+		t.Errorf("Expected to wait about 20ms but got %v", elapsed)
+	}
+	if err := l.Wait(ctx, "example.org"); err != nil {
+		t.Errorf("Expected nil but got %v", err)
+	}
+	stats := l.Stats()
+	for _, test := range []struct {
+		actual   interface{}
+		expected interface{}
+	}{
+		{len(stats), 2},
+		{stats["example.com"].Allowed, int64(3)},
+		{stats["example.com"].Rejected, int64(0)},
+		{stats["example.com"].Waiting, 0},
+		{stats["example.org"].Allowed, int64(1)},
+		{stats["example.org"].Wait, time.Duration(0)},
+	} {
+		if test.actual != test.expected {
+			t.Errorf(`Expected "%v" but got "%v"`, test.expected, test.actual)
+		}
+	}
+}
+
+func TestRateLimiterReject(t *testing.T) {
+	l := NewRateLimiter(1, 1)
+	l.MaxWait = 10 * time.Millisecond
+	if err := l.Wait(context.Background(), "example.com"); err != nil {
+		t.Errorf("Expected nil but got %v", err)
+	}
+	if err := l.Wait(context.Background(), "example.com"); err != ErrRateLimited {
+		t.Errorf(`Expected "%v" but got "%v"`, ErrRateLimited, err)
+	}
+	l.MaxWait = 0
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := l.Wait(ctx, "example.com"); err != ErrRateLimited {
+		t.Errorf(`Expected "%v" but got "%v"`, ErrRateLimited, err)
+	}
+	stats := l.Stats()["example.com"]
+	if stats.Rejected != 2 {
+		t.Errorf("Expected 2 but got %v", stats.Rejected)
+	}
+	if stats.Wait < 900*time.Millisecond || stats.Wait > time.Second {
+		t.Errorf("Expected about 1s but got %v", stats.Wait)
+	}
+}
+
+func TestRateLimiterContextCanceled(t *testing.T) {
+	l := NewRateLimiter(1, 1)
+	l.Wait(context.Background(), "example.com")
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		cancel()
+	}()
+	if err := l.Wait(ctx, "example.com"); err != context.Canceled {
+		t.Errorf(`Expected "%v" but got "%v"`, context.Canceled, err)
+	}
+	stats := l.Stats()["example.com"]
+	if stats.Rejected != 1 || stats.Waiting != 0 || stats.Allowed != 1 {
+		t.Errorf("Unexpected stats %+v", stats)
+	}
+}
+
+func TestRateLimiterUnlimited(t *testing.T) {
+	l := &RateLimiter{}
+	for i := 0; i < 100; i++ {
+		if err := l.Wait(context.Background(), "example.com"); err != nil {
+			t.Errorf("Expected nil but got %v", err)
+		}
+	}
+	if stats := l.Stats()["example.com"]; stats.Allowed != 100 || stats.Wait != 0 {
+		t.Errorf("Unexpected stats %+v", stats)
+	}
+}
+
+func TestServiceRateLimiter(t *testing.T) {
+	defer gock.Off()
+	gock.New("https://apps-apis.google.com").
+		Get("/a/feeds/compliance/audit/mail/monitor/example.com/abhishek").
+		Reply(200).
+		XML(monitorsXML)
+
+	limiter := NewRateLimiter(1, 1)
+	limiter.MaxWait = time.Millisecond
+	svc := newTestService(WithRateLimiter(limiter))
+	if _, err := svc.MailMonitor.List("example.com", "abhishek"); err != nil {
+		t.Errorf("Expected nil but got %v", err)
+	}
+	if _, err := svc.MailMonitor.List("example.com", "abhishek"); err != ErrRateLimited {
+		t.Errorf(`Expected "%v" but got "%v"`, ErrRateLimited, err)
+	}
+	stats := limiter.Stats()["example.com"]
+	if stats.Allowed != 1 || stats.Rejected != 1 {
+		t.Errorf("Unexpected stats %+v", stats)
+	}
+}
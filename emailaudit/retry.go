@@ -0,0 +1,152 @@
+package emailaudit
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// Logger logs retries of requests. *log.Logger satisfies Logger.
+type Logger interface {
+	Printf(format string, v ...interface{})
+}
+
+// RetryPolicy retries requests failed with network errors or
+// 429, 500, 502, 503 and 504 responses
+type RetryPolicy struct {
+	// MaxAttempts is the number of attempts including the first one
+	MaxAttempts int
+	// Backoff defaults to 1 second initial, 30 seconds max and multiplier 2 for zero fields
+	Backoff Backoff
+	// MaxRetryAfter caps the delay requested by Retry-After headers (default 5 minutes)
+	MaxRetryAfter time.Duration
+	// Jitter randomizes each delay by up to the fraction of it, e.g. 0.2 for ±20%
+	Jitter float64
+	// RetryPOST also retries POST requests, which are not idempotent
+	RetryPOST bool
+}
+
+// DefaultRetryPolicy returns RetryPolicy retrying GET and DELETE requests up to 4 attempts
+func DefaultRetryPolicy() *RetryPolicy {
+	return &RetryPolicy{
+		MaxAttempts:   4,
+		Backoff:       defaultRetryBackoff(),
+		MaxRetryAfter: defaultMaxRetryAfter,
+		Jitter:        0.2,
+	}
+}
+
+const defaultMaxRetryAfter = 5 * time.Minute
+
+func defaultRetryBackoff() Backoff {
+	return Backoff{
+		Initial:    time.Second,
+		Max:        30 * time.Second,
+		Multiplier: 2,
+	}
+}
+
+// RetryError is returned when a request failed after more than one attempt
+type RetryError struct {
+	Attempts int
+	Err      error
+}
+
+func (e *RetryError) Error() string {
+	return fmt.Sprintf("%v (after %d attempts)", e.Err, e.Attempts)
+}
+
+// Unwrap returns the error of the last attempt
+func (e *RetryError) Unwrap() error {
+	return e.Err
+}
+
+func retryError(attempts int, err error) error {
+	if err == nil || attempts <= 1 {
+		return err
+	}
+	return &RetryError{Attempts: attempts, Err: err}
+}
+
+func (p *RetryPolicy) maxAttempts(method string) int {
+	if p == nil || p.MaxAttempts < 1 {
+		return 1
+	}
+	switch method {
+	case "GET", "DELETE":
+		return p.MaxAttempts
+	case "POST":
+		if p.RetryPOST {
+			return p.MaxAttempts
+		}
+	}
+	return 1
+}
+
+// delay returns the delay before the given retry (0 origin), preferring Retry-After of res
+func (p *RetryPolicy) delay(retry int, res *http.Response, now time.Time) time.Duration {
+	if d, ok := retryAfter(res, now); ok {
+		max := p.MaxRetryAfter
+		if max <= 0 {
+			max = defaultMaxRetryAfter
+		}
+		if d > max {
+			d = max
+		}
+		return d
+	}
+	d := float64(p.Backoff.withDefaults(defaultRetryBackoff()).Duration(retry))
+	if p.Jitter > 0 {
+		d += d * p.Jitter * (2*rand.Float64() - 1)
+	}
+	return time.Duration(d)
+}
+
+func isRetryableStatus(code int) bool {
+	switch code {
+	case http.StatusTooManyRequests,
+		http.StatusInternalServerError,
+		http.StatusBadGateway,
+		http.StatusServiceUnavailable,
+		http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// isRetryableError reports whether err is a network error, a rate limit error
+// or an APIError with a retryable status
+func isRetryableError(err error) bool {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return isRetryableStatus(apiErr.StatusCode)
+	}
+	var urlErr *url.Error
+	return errors.As(err, &urlErr) || errors.Is(err, ErrRateLimited)
+}
+
+// retryAfter parses Retry-After header of res in seconds or HTTP date
+func retryAfter(res *http.Response, now time.Time) (time.Duration, bool) {
+	if res == nil {
+		return 0, false
+	}
+	value := res.Header.Get("Retry-After")
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+	if t, err := http.ParseTime(value); err == nil {
+		d := t.Sub(now)
+		if d < 0 {
+			d = 0
+		}
+		return d, true
+	}
+	return 0, false
+}
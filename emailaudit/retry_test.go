@@ -0,0 +1,223 @@
+package emailaudit
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	gock "gopkg.in/h2non/gock.v1"
+)
+
+type testLogger struct {
+	lines []string
+}
+
+func (l *testLogger) Printf(format string, v ...interface{}) {
+	l.lines = append(l.lines, fmt.Sprintf(format, v...))
+}
+
+func newRetryTestService(retryPOST bool) (*Service, *testLogger) {
+	logger := &testLogger{}
+	svc := newTestService(WithRetryPolicy(&RetryPolicy{
+		MaxAttempts: 3,
+		Backoff:     Backoff{Initial: time.Millisecond, Max: time.Millisecond, Multiplier: 1},
+		RetryPOST:   retryPOST,
+	}), WithLogger(logger))
+	return svc, logger
+}
+
+func TestServiceRetry(t *testing.T) {
+	defer gock.Off()
+	gock.New("https://apps-apis.google.com").
+		Get("/a/feeds/compliance/audit/mail/monitor/example.com/abhishek").
+		Reply(503).
+		BodyString("Unavailable")
+	gock.New("https://apps-apis.google.com").
+		Get("/a/feeds/compliance/audit/mail/monitor/example.com/abhishek").
+		Reply(429).
+		SetHeader("Retry-After", "0").
+		BodyString("Quota")
+	gock.New("https://apps-apis.google.com").
+		Get("/a/feeds/compliance/audit/mail/monitor/example.com/abhishek").
+		MatchHeader("Authorization", "Bearer test").
+		Reply(200).
+		XML(monitorsXML)
+
+	svc, logger := newRetryTestService(false)
+	m, err := svc.MailMonitor.List("example.com", "abhishek")
+	if err != nil {
+		t.Errorf("Expected nil but got %v", err)
+	}
+	_TestMonitors(m, t)
+	if len(logger.lines) != 2 {
+		t.Fatalf("Expected 2 but got %v", len(logger.lines))
+	}
+	for i, expected := range []string{
+		"emailaudit: GET https://apps-apis.google.com/a/feeds/compliance/audit/mail/monitor/example.com/abhishek returned 503, retrying in 1ms (attempt 1 of 3)",
+		"emailaudit: GET https://apps-apis.google.com/a/feeds/compliance/audit/mail/monitor/example.com/abhishek returned 429, retrying in 0s (attempt 2 of 3)",
+	} {
+		if logger.lines[i] != expected {
+			t.Errorf(`Expected "%v" but got "%v"`, expected, logger.lines[i])
+		}
+	}
+}
+
+func TestServiceRetryExhausted(t *testing.T) {
+	defer gock.Off()
+	gock.New("https://apps-apis.google.com").
+		Delete("/a/feeds/compliance/audit/mail/monitor/example.com/abhishek/namrata").
+		Times(3).
+		Reply(503).
+		BodyString("Omg")
+
+	svc, logger := newRetryTestService(false)
+	err := svc.MailMonitor.Disable("example.com", "abhishek", "namrata")
+	expected := "503 Service Unavailable: Omg (after 3 attempts)"
+	if err == nil || err.Error() != expected {
+		t.Errorf(`Expected "%v" but got "%v"`, expected, err)
+	}
+	var retryErr *RetryError
+	if !errors.As(err, &retryErr) || retryErr.Attempts != 3 {
+		t.Errorf("Expected *RetryError with 3 attempts but got %v", err)
+	}
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != 503 {
+		t.Errorf("Expected *APIError with status 503 but got %v", err)
+	}
+	if len(logger.lines) != 2 {
+		t.Errorf("Expected 2 but got %v", len(logger.lines))
+	}
+}
+
+func TestServiceRetryNotRetryableStatus(t *testing.T) {
+	defer gock.Off()
+	gock.New("https://apps-apis.google.com").
+		Get("/a/feeds/compliance/audit/mail/monitor/example.com/abhishek").
+		Reply(400).
+		BodyString("Omg")
+
+	svc, logger := newRetryTestService(false)
+	_, err := svc.MailMonitor.List("example.com", "abhishek")
+	expected := "400 Bad Request: Omg"
+	if err == nil || err.Error() != expected {
+		t.Errorf(`Expected "%v" but got "%v"`, expected, err)
+	}
+	if len(logger.lines) != 0 {
+		t.Errorf("Expected 0 but got %v", len(logger.lines))
+	}
+}
+
+func TestServiceRetryPOST(t *testing.T) {
+	endDate := time.Date(2116, time.October, 30, 14, 59, 0, 0, time.UTC)
+	levels := MailMonitorLevels{Chat: HeaderOnlyLevel}
+	for _, test := range []struct {
+		retryPOST bool
+		expected  string
+	}{
+		{false, "503 Service Unavailable: Unavailable"},
+		{true, ""},
+	} {
+		gock.New("https://apps-apis.google.com").
+			Post("/a/feeds/compliance/audit/mail/monitor/example.com/abhishek").
+			Reply(503).
+			BodyString("Unavailable")
+		gock.New("https://apps-apis.google.com").
+			Post("/a/feeds/compliance/audit/mail/monitor/example.com/abhishek").
+			Reply(200).
+			XML(monitorXML)
+
+		svc, _ := newRetryTestService(test.retryPOST)
+		_, err := svc.MailMonitor.Update("example.com", "abhishek", "namrata", endDate, levels)
+		actual := ""
+		if err != nil {
+			actual = err.Error()
+		}
+		if actual != test.expected {
+			t.Errorf(`Expected "%v" but got "%v"`, test.expected, actual)
+		}
+		gock.Off()
+	}
+}
+
+func TestRetryPolicyDelay(t *testing.T) {
+	p := &RetryPolicy{Backoff: Backoff{Initial: time.Second, Max: time.Minute, Multiplier: 2}, Jitter: 0.5}
+	for i := 0; i < 100; i++ {
+		d := p.delay(1, nil, time.Now())
+		if d < time.Second || d > 3*time.Second {
+			t.Errorf("Expected between 1s and 3s but got %v", d)
+		}
+	}
+	res := &http.Response{Header: http.Header{"Retry-After": {"120"}}}
+	if d := p.delay(1, res, time.Now()); d != 2*time.Minute {
+		t.Errorf(`Expected "%v" but got "%v"`, 2*time.Minute, d)
+	}
+}
+
+func TestRetryPolicyDelayDefaults(t *testing.T) {
+	p := &RetryPolicy{}
+	for _, test := range []struct {
+		actual   interface{}
+		expected interface{}
+	}{
+		{p.delay(0, nil, time.Now()), time.Second},
+		{p.delay(2, nil, time.Now()), 4 * time.Second},
+		{p.delay(10, nil, time.Now()), 30 * time.Second},
+		{p.delay(0, &http.Response{Header: http.Header{"Retry-After": {"86400"}}}, time.Now()), 5 * time.Minute},
+		{(&RetryPolicy{MaxRetryAfter: time.Minute}).delay(0, &http.Response{Header: http.Header{"Retry-After": {"120"}}}, time.Now()), time.Minute},
+	} {
+		if test.actual != test.expected {
+			t.Errorf(`Expected "%v" but got "%v"`, test.expected, test.actual)
+		}
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	now := time.Date(2016, time.October, 30, 14, 59, 0, 0, time.UTC)
+	future := now.Add(time.Hour).Format(http.TimeFormat)
+	past := now.Add(-time.Hour).Format(http.TimeFormat)
+	for _, test := range []struct {
+		value    string
+		ok       bool
+		expected func(d time.Duration) bool
+	}{
+		{"", false, func(d time.Duration) bool { return d == 0 }},
+		{"30", true, func(d time.Duration) bool { return d == 30*time.Second }},
+		{"-1", false, func(d time.Duration) bool { return d == 0 }},
+		{"soon", false, func(d time.Duration) bool { return d == 0 }},
+		{future, true, func(d time.Duration) bool { return d == time.Hour }},
+		{past, true, func(d time.Duration) bool { return d == 0 }},
+	} {
+		res := &http.Response{Header: http.Header{}}
+		if test.value != "" {
+			res.Header.Set("Retry-After", test.value)
+		}
+		d, ok := retryAfter(res, now)
+		if ok != test.ok || !test.expected(d) {
+			t.Errorf(`Unexpected "%v" "%v" for "%v"`, d, ok, test.value)
+		}
+	}
+}
+
+func TestRetryPolicyMaxAttempts(t *testing.T) {
+	var nilPolicy *RetryPolicy
+	for _, test := range []struct {
+		actual   interface{}
+		expected interface{}
+	}{
+		{nilPolicy.maxAttempts("GET"), 1},
+		{DefaultRetryPolicy().maxAttempts("GET"), 4},
+		{DefaultRetryPolicy().maxAttempts("DELETE"), 4},
+		{DefaultRetryPolicy().maxAttempts("POST"), 1},
+		{(&RetryPolicy{MaxAttempts: 2, RetryPOST: true}).maxAttempts("POST"), 2},
+		{(&RetryPolicy{}).maxAttempts("GET"), 1},
+		{strings.Contains(retryError(2, errors.New("Omg")).Error(), "after 2 attempts"), true},
+		{retryError(1, nil), nil},
+	} {
+		if test.actual != test.expected {
+			t.Errorf(`Expected "%v" but got "%v"`, test.expected, test.actual)
+		}
+	}
+}
@@ -2,10 +2,14 @@ package emailaudit
 
 import (
 	"bytes"
+	"context"
+	"encoding/xml"
 	"errors"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"net/http"
+	"strings"
 	"time"
 
 	"google.golang.org/api/googleapi"
@@ -13,11 +17,23 @@ import (
 
 const contentType = "application/atom+xml"
 
-// Service Service
+// DefaultBaseURL is the endpoint of Email Audit API used unless WithBaseURL is given
+const DefaultBaseURL = "https://apps-apis.google.com/a/feeds/compliance/audit"
+
+// Service Service. Its configuration is set in New and can't be changed afterwards,
+// so it is safe to share between goroutines. The sub-service fields are set once by New.
 type Service struct {
 	client      *http.Client
 	MailMonitor *MailMonitorService
-	UserAgent   string
+	Export      *MailboxExportService
+	PublicKey   *PublicKeyService
+	AccountInfo *AccountInfoService
+	userAgent   string
+	retryPolicy *RetryPolicy
+	logger      Logger
+	rateLimiter *RateLimiter
+	baseURL     string
+	clock       Clock
 }
 
 // MailMonitorService MailMonitorService
@@ -25,21 +41,131 @@ type MailMonitorService struct {
 	s *Service
 }
 
-// New returns new Service
-func New(client *http.Client) (*Service, error) {
+// New returns new Service configured with opts
+func New(client *http.Client, opts ...Option) (*Service, error) {
 	if client == nil {
 		return nil, errors.New("client is nil")
 	}
-	s := &Service{client: client}
+	s := &Service{client: client, clock: systemClock{}}
+	for _, opt := range opts {
+		opt.apply(s)
+	}
 	s.MailMonitor = NewMailMonitorService(s)
+	s.Export = NewMailboxExportService(s)
+	s.PublicKey = NewPublicKeyService(s)
+	s.AccountInfo = NewAccountInfoService(s)
 	return s, nil
 }
 
-func (s *Service) userAgent() string {
-	if s.UserAgent == "" {
+// endpoint returns URL of the path of the API under baseURL, which defaults to DefaultBaseURL
+func endpoint(baseURL string, path string) string {
+	if baseURL == "" {
+		baseURL = DefaultBaseURL
+	}
+	return strings.TrimSuffix(baseURL, "/") + "/" + path
+}
+
+func (s *Service) endpoint(path string) string {
+	return endpoint(s.baseURL, path)
+}
+
+func (s *Service) userAgentHeader() string {
+	if s.userAgent == "" {
 		return googleapi.UserAgent
 	}
-	return googleapi.UserAgent + " " + s.UserAgent
+	return googleapi.UserAgent + " " + s.userAgent
+}
+
+func (s *Service) do(ctx context.Context, domain string, method string, url string, body []byte) ([]byte, error) {
+	res, attempts, err := s.send(ctx, domain, method, url, body, nil)
+	if err != nil {
+		return nil, retryError(attempts, err)
+	}
+	defer res.Body.Close()
+	if !(res.StatusCode >= 200 && res.StatusCode < 300) {
+		return nil, retryError(attempts, responseError(res))
+	}
+	return ioutil.ReadAll(res.Body)
+}
+
+// stream sends a GET request and returns the response as is.
+// The caller must close the response body.
+func (s *Service) stream(ctx context.Context, domain string, url string, header http.Header) (*http.Response, error) {
+	res, attempts, err := s.send(ctx, domain, "GET", url, nil, header)
+	return res, retryError(attempts, err)
+}
+
+// send sends a request to the domain, waiting for the rate limiter and retrying it according to the retry policy,
+// and returns the last response with the number of attempts
+func (s *Service) send(ctx context.Context, domain string, method string, url string, body []byte, header http.Header) (*http.Response, int, error) {
+	maxAttempts := s.retryPolicy.maxAttempts(method)
+	for attempt := 1; ; attempt++ {
+		if s.rateLimiter != nil {
+			if err := s.rateLimiter.Wait(ctx, domain); err != nil {
+				return nil, attempt, err
+			}
+		}
+		var r io.Reader
+		if body != nil {
+			r = bytes.NewReader(body)
+		}
+		req, err := http.NewRequestWithContext(ctx, method, url, r)
+		if err != nil {
+			return nil, attempt, err
+		}
+		for k, v := range header {
+			req.Header[k] = v
+		}
+		req.Header.Add("User-Agent", s.userAgentHeader())
+		if body != nil {
+			req.Header.Add("Content-Type", contentType)
+		}
+		res, err := s.client.Do(req)
+		if attempt >= maxAttempts || ctx.Err() != nil {
+			return res, attempt, err
+		}
+		if err == nil && !isRetryableStatus(res.StatusCode) {
+			return res, attempt, nil
+		}
+		delay := s.retryPolicy.delay(attempt-1, res, s.clock.Now())
+		if err == nil {
+			io.Copy(ioutil.Discard, res.Body)
+			res.Body.Close()
+			s.logf("emailaudit: %v %v returned %v, retrying in %v (attempt %d of %d)", method, url, res.StatusCode, delay, attempt, maxAttempts)
+		} else {
+			s.logf("emailaudit: %v %v failed: %v, retrying in %v (attempt %d of %d)", method, url, err, delay, attempt, maxAttempts)
+		}
+		if err := s.sleep(ctx, delay); err != nil {
+			return nil, attempt, err
+		}
+	}
+}
+
+func (s *Service) logf(format string, v ...interface{}) {
+	if s.logger != nil {
+		s.logger.Printf(format, v...)
+	}
+}
+
+// getFeed retrieves entries of an Atom feed, following its next links until exhausted
+// or until a next link points to a page already retrieved
+func (s *Service) getFeed(ctx context.Context, domain string, url string) ([]monitorReadProperties, error) {
+	var entries []monitorReadProperties
+	visited := map[string]bool{}
+	for url != "" && !visited[url] {
+		visited[url] = true
+		bytes, err := s.do(ctx, domain, "GET", url, nil)
+		if err != nil {
+			return nil, err
+		}
+		var list monitorReadListProperties
+		if err := xml.Unmarshal(bytes, &list); err != nil {
+			return nil, err
+		}
+		entries = append(entries, list.Entries...)
+		url = list.nextURL()
+	}
+	return entries, nil
 }
 
 // NewMailMonitorService returns new MailMonitorService
@@ -48,57 +174,118 @@ func NewMailMonitorService(s *Service) *MailMonitorService {
 	return rs
 }
 
-// Update creates or updates EmailMonitor
+// Update creates or updates EmailMonitor.
+// ValidationError is returned without sending a request if the monitor is invalid.
 // - https://developers.google.com/admin-sdk/email-audit/#creating_a_new_email_monitor
 // - https://developers.google.com/admin-sdk/email-audit/#updating_an_email_monitor
 func (svc *MailMonitorService) Update(domainName string, sourceUserName string, destUserName string, endDate time.Time, monitorLevels MailMonitorLevels) (*MailMonitor, error) {
+	return svc.UpdateContext(context.Background(), domainName, sourceUserName, destUserName, endDate, monitorLevels)
+}
+
+// UpdateContext is Update with a context
+func (svc *MailMonitorService) UpdateContext(ctx context.Context, domainName string, sourceUserName string, destUserName string, endDate time.Time, monitorLevels MailMonitorLevels) (*MailMonitor, error) {
 	monitor := NewMailMonitor(domainName, sourceUserName, destUserName, endDate, monitorLevels)
-	url := monitor.URL()
-	body := bytes.NewReader(monitor.toXML())
-	req, _ := http.NewRequest("POST", url, body)
-	req.Header.Add("User-Agent", svc.s.userAgent())
-	req.Header.Add("Content-Type", contentType)
-	res, err := svc.s.client.Do(req)
+	return svc.update(ctx, monitor)
+}
+
+func (svc *MailMonitorService) update(ctx context.Context, monitor MailMonitor) (*MailMonitor, error) {
+	if err := monitor.validate(svc.s.clock.Now()); err != nil {
+		return nil, err
+	}
+	monitor.baseURL = svc.s.baseURL
+	bytes, err := svc.s.do(ctx, monitor.DomainName, "POST", monitor.URL(), monitor.toXML())
 	if err != nil {
 		return nil, err
 	}
-	bytes, _ := ioutil.ReadAll(res.Body)
-	if !(res.StatusCode >= 200 && res.StatusCode < 300) {
-		return nil, errors.New(string(bytes))
+	return svc.monitorFromXML(bytes)
+}
+
+// Get retrieves an email monitor of a source user to a destination user.
+// MailMonitorNotFoundError is returned if the monitor does not exist.
+func (svc *MailMonitorService) Get(domain string, sourceUserName string, destUserName string) (*MailMonitor, error) {
+	return svc.GetContext(context.Background(), domain, sourceUserName, destUserName)
+}
+
+// GetContext is Get with a context
+func (svc *MailMonitorService) GetContext(ctx context.Context, domain string, sourceUserName string, destUserName string) (*MailMonitor, error) {
+	url := fmt.Sprintf("%v/%v/%v/%v", svc.s.endpoint(mailMonitorPath), domain, sourceUserName, destUserName)
+	bytes, err := svc.s.do(ctx, domain, "GET", url, nil)
+	if errors.Is(err, ErrEntityDoesNotExist) {
+		return nil, &MailMonitorNotFoundError{DomainName: domain, SourceUserName: sourceUserName, DestUserName: destUserName, Err: err}
+	}
+	if err != nil {
+		return nil, err
 	}
-	return monitorFromXML(bytes)
+	return svc.monitorFromXML(bytes)
 }
 
-// List Retrieving all email monitors of a source user
+// Patch changes the non-nil fields of patch of an existing email monitor,
+// leaving the other fields as they are, and returns the monitor before and after the change.
+// MailMonitorNotFoundError is returned if the monitor does not exist.
+func (svc *MailMonitorService) Patch(domain string, sourceUserName string, destUserName string, patch MailMonitorPatch) (*MailMonitor, *MailMonitor, error) {
+	return svc.PatchContext(context.Background(), domain, sourceUserName, destUserName, patch)
+}
+
+// PatchContext is Patch with a context
+func (svc *MailMonitorService) PatchContext(ctx context.Context, domain string, sourceUserName string, destUserName string, patch MailMonitorPatch) (*MailMonitor, *MailMonitor, error) {
+	before, err := svc.GetContext(ctx, domain, sourceUserName, destUserName)
+	if err != nil {
+		return nil, nil, err
+	}
+	monitor := patch.apply(*before)
+	if patch.BeginDate == nil && monitor.BeginDate != nil && !monitor.BeginDate.After(svc.s.clock.Now()) {
+		// the monitor has already begun, so its beginDate is not sent again
+		monitor.BeginDate = nil
+	}
+	monitor.DomainName = domain
+	monitor.SourceUserName = sourceUserName
+	monitor.DestUserName = destUserName
+	after, err := svc.update(ctx, monitor)
+	if err != nil {
+		return before, nil, err
+	}
+	return before, after, nil
+}
+
+// List Retrieving all email monitors of a source user, following next links of the feed
 // - https://developers.google.com/admin-sdk/email-audit/#retrieving_all_email_monitors_of_a_source_user
 func (svc *MailMonitorService) List(domain string, sourceUserName string) ([]MailMonitor, error) {
-	url := fmt.Sprintf("%v/%v/%v", baseURL, domain, sourceUserName)
-	req, _ := http.NewRequest("GET", url, nil)
-	req.Header.Add("User-Agent", svc.s.userAgent())
-	res, err := svc.s.client.Do(req)
+	return svc.ListContext(context.Background(), domain, sourceUserName)
+}
+
+// ListContext is List with a context
+func (svc *MailMonitorService) ListContext(ctx context.Context, domain string, sourceUserName string) ([]MailMonitor, error) {
+	url := fmt.Sprintf("%v/%v/%v", svc.s.endpoint(mailMonitorPath), domain, sourceUserName)
+	entries, err := svc.s.getFeed(ctx, domain, url)
 	if err != nil {
 		return nil, err
 	}
-	bytes, _ := ioutil.ReadAll(res.Body)
-	if !(res.StatusCode >= 200 && res.StatusCode < 300) {
-		return nil, errors.New(string(bytes))
+	var monitors []MailMonitor
+	for _, entry := range entries {
+		m := entry.toMonitor()
+		m.baseURL = svc.s.baseURL
+		monitors = append(monitors, m)
 	}
-	return monitorsFromXML(bytes)
+	return monitors, nil
 }
 
 // Disable Deleting an email monitor
 // - https://developers.google.com/admin-sdk/email-audit/#deleting_an_email_monitor
 func (svc *MailMonitorService) Disable(domain string, sourceUserName string, destUserName string) error {
-	url := fmt.Sprintf("%v/%v/%v/%v", baseURL, domain, sourceUserName, destUserName)
-	req, _ := http.NewRequest("DELETE", url, nil)
-	req.Header.Add("User-Agent", svc.s.userAgent())
-	res, err := svc.s.client.Do(req)
-	if err != nil {
-		return err
-	}
-	bytes, err := ioutil.ReadAll(res.Body)
-	if !(res.StatusCode >= 200 && res.StatusCode < 300) {
-		return errors.New(string(bytes))
-	}
+	return svc.DisableContext(context.Background(), domain, sourceUserName, destUserName)
+}
+
+// DisableContext is Disable with a context
+func (svc *MailMonitorService) DisableContext(ctx context.Context, domain string, sourceUserName string, destUserName string) error {
+	url := fmt.Sprintf("%v/%v/%v/%v", svc.s.endpoint(mailMonitorPath), domain, sourceUserName, destUserName)
+	_, err := svc.s.do(ctx, domain, "DELETE", url, nil)
 	return err
 }
+
+func (svc *MailMonitorService) monitorFromXML(data []byte) (*MailMonitor, error) {
+	m, err := monitorFromXML(data)
+	if m != nil {
+		m.baseURL = svc.s.baseURL
+	}
+	return m, err
+}
@@ -1,13 +1,16 @@
 package emailaudit
 
 import (
+	"context"
 	"errors"
+	"io/ioutil"
 	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
 	"testing"
 	"time"
 
-	"golang.org/x/net/context"
-
 	"golang.org/x/oauth2"
 
 	gock "gopkg.in/h2non/gock.v1"
@@ -39,14 +42,13 @@ func TestServiceUserAgent(t *testing.T) {
 	client := &http.Client{}
 	svc, _ := New(client)
 	expected := "google-api-go-client/0.5"
-	if svc.userAgent() != expected {
-		t.Errorf(`Expected "%v" but got "%v"`, expected, svc.userAgent())
+	if svc.userAgentHeader() != expected {
+		t.Errorf(`Expected "%v" but got "%v"`, expected, svc.userAgentHeader())
 	}
-	svc, _ = New(client)
+	svc, _ = New(client, WithUserAgent("foo"))
 	expected = "google-api-go-client/0.5 foo"
-	svc.UserAgent = "foo"
-	if svc.userAgent() != expected {
-		t.Errorf(`Expected "%v" but got "%v"`, expected, svc.userAgent())
+	if svc.userAgentHeader() != expected {
+		t.Errorf(`Expected "%v" but got "%v"`, expected, svc.userAgentHeader())
 	}
 }
 
@@ -56,7 +58,7 @@ func updateEmailMonitor() (*MailMonitor, error) {
 	token := &oauth2.Token{AccessToken: "test"}
 	client := config.Client(ctx, token)
 	svc, _ := New(client)
-	endDate := time.Date(2016, time.October, 30, 14, 59, 0, 0, time.UTC)
+	endDate := time.Date(2116, time.October, 30, 14, 59, 0, 0, time.UTC)
 	return svc.MailMonitor.Update("example.com", "abhishek", "namrata", endDate, MailMonitorLevels{
 		IncomingEmail: HeaderOnlyLevel,
 		OutgoingEmail: HeaderOnlyLevel,
@@ -116,6 +118,35 @@ func TestMailMonitorServiceList(t *testing.T) {
 	_TestMonitors(m, t)
 }
 
+func TestMailMonitorServiceListPages(t *testing.T) {
+	defer gock.Off()
+	gock.New("https://apps-apis.google.com").
+		Get("/a/feeds/compliance/audit/mail/monitor/example.com/abhishek").
+		MatchParam("start", "2").
+		Reply(200).
+		XML(`<feed xmlns="http://www.w3.org/2005/Atom" xmlns:apps="http://schemas.google.com/apps/2006">
+<link rel="next" type="application/atom+xml" href="https://apps-apis.google.com/a/feeds/compliance/audit/mail/monitor/example.com/abhishek?start=2"/>
+<entry>
+  <id>https://apps-apis.google.com/a/feeds/compliance/audit/mail/monitor/example.com/abhishek/zoe</id>
+  <apps:property name="destUserName" value="zoe"/>
+</entry>
+</feed>`)
+	gock.New("https://apps-apis.google.com").
+		Get("/a/feeds/compliance/audit/mail/monitor/example.com/abhishek").
+		Reply(200).
+		XML(strings.Replace(monitorsXML, "<entry>",
+			`<link rel="next" type="application/atom+xml" href="https://apps-apis.google.com/a/feeds/compliance/audit/mail/monitor/example.com/abhishek?start=2"/>
+<entry>`, 1))
+
+	m, err := newTestService().MailMonitor.List("example.com", "abhishek")
+	if err != nil {
+		t.Errorf("Expected nil but got %v", err)
+	}
+	if len(m) != 3 || m[2].DestUserName != "zoe" || m[2].SourceUserName != "abhishek" {
+		t.Errorf("Expected 3 monitors ending with zoe but got %v", m)
+	}
+}
+
 func TestMailMonitorServiceDisable(t *testing.T) {
 	defer gock.Off()
 	gock.New("https://apps-apis.google.com").
@@ -130,6 +161,230 @@ func TestMailMonitorServiceDisable(t *testing.T) {
 	}
 }
 
+func TestMailMonitorServiceGet(t *testing.T) {
+	defer gock.Off()
+	gock.New("https://apps-apis.google.com").
+		Get("/a/feeds/compliance/audit/mail/monitor/example.com/abhishek/namrata").
+		MatchHeader("Authorization", "Bearer test").
+		MatchHeader("User-Agent", "google-api-go-client/0.5").
+		Reply(200).
+		XML(monitorXML)
+
+	m, err := newTestService().MailMonitor.Get("example.com", "abhishek", "namrata")
+	if err != nil {
+		t.Errorf("Expected nil but got %v", err)
+	}
+	_TestMonitor(m, t)
+}
+
+func TestMailMonitorServiceGetNotFound(t *testing.T) {
+	defer gock.Off()
+	for _, test := range []struct {
+		status int
+		body   string
+	}{
+		{404, "Not Found"},
+		{400, `<?xml version="1.0" encoding="UTF-8"?><AppsForYourDomainErrors><error errorCode="1301" invalidInput="namrata" reason="EntityDoesNotExist" /></AppsForYourDomainErrors>`},
+	} {
+		gock.New("https://apps-apis.google.com").
+			Get("/a/feeds/compliance/audit/mail/monitor/example.com/abhishek/namrata").
+			Reply(test.status).
+			BodyString(test.body)
+
+		m, err := newTestService().MailMonitor.Get("example.com", "abhishek", "namrata")
+		if m != nil {
+			t.Errorf("Expected nil but got %v", m)
+		}
+		nf, ok := err.(*MailMonitorNotFoundError)
+		if !ok {
+			t.Errorf("Expected *MailMonitorNotFoundError but got %T", err)
+			continue
+		}
+		expected := "email monitor of abhishek@example.com to namrata does not exist"
+		if nf.Error() != expected {
+			t.Errorf(`Expected "%v" but got "%v"`, expected, nf.Error())
+		}
+		gock.Off()
+	}
+}
+
+func TestMailMonitorServiceGetHTTPErrorResponse(t *testing.T) {
+	defer gock.Off()
+	gock.New("https://apps-apis.google.com").
+		Get("/a/feeds/compliance/audit/mail/monitor/example.com/abhishek/namrata").
+		Reply(400).
+		BodyString("Omg")
+
+	m, err := newTestService().MailMonitor.Get("example.com", "abhishek", "namrata")
+	expected := "400 Bad Request: Omg"
+	if err.Error() != expected {
+		t.Errorf(`Expected "%v" but got "%v"`, expected, err)
+	}
+	if m != nil {
+		t.Errorf("Expected nil but got %v", m)
+	}
+}
+
+func TestMailMonitorServicePatch(t *testing.T) {
+	defer gock.Off()
+	gock.New("https://apps-apis.google.com").
+		Get("/a/feeds/compliance/audit/mail/monitor/example.com/abhishek/namrata").
+		MatchHeader("Authorization", "Bearer test").
+		Reply(200).
+		XML(monitorXML)
+	var body string
+	gock.New("https://apps-apis.google.com").
+		Post("/a/feeds/compliance/audit/mail/monitor/example.com/abhishek").
+		MatchType("application/atom\\+xml").
+		AddMatcher(func(req *http.Request, ereq *gock.Request) (bool, error) {
+			b, err := ioutil.ReadAll(req.Body)
+			body = string(b)
+			return true, err
+		}).
+		Reply(200).
+		XML(strings.Replace(monitorXML, `name="chatMonitorLevel" value="FULL_MESSAGE"`, `name="chatMonitorLevel" value="HEADER_ONLY"`, 1))
+
+	chat := HeaderOnlyLevel
+	endDate := time.Date(2116, time.October, 30, 14, 59, 0, 0, time.UTC)
+	before, after, err := newTestService().MailMonitor.Patch("example.com", "abhishek", "namrata", MailMonitorPatch{Chat: &chat, EndDate: &endDate})
+	if err != nil {
+		t.Errorf("Expected nil but got %v", err)
+	}
+	_TestMonitor(before, t)
+	for _, test := range []struct {
+		actual   interface{}
+		expected interface{}
+	}{
+		{after.MonitorLevels.Chat, HeaderOnlyLevel},
+		{after.MonitorLevels.IncomingEmail, FullMessageLevel},
+		{strings.Contains(body, `<apps:property name="destUserName" value="namrata"></apps:property>`), true},
+		{strings.Contains(body, `<apps:property name="endDate" value="2116-10-30 14:59"></apps:property>`), true},
+		{strings.Contains(body, `<apps:property name="incomingEmailMonitorLevel" value="FULL_MESSAGE"></apps:property>`), true},
+		{strings.Contains(body, `<apps:property name="chatMonitorLevel" value="HEADER_ONLY"></apps:property>`), true},
+		{strings.Contains(body, "beginDate"), false},
+	} {
+		if test.actual != test.expected {
+			t.Errorf(`Expected "%v" but got "%v"`, test.expected, test.actual)
+		}
+	}
+}
+
+func TestMailMonitorServicePatchBeginDate(t *testing.T) {
+	defer gock.Off()
+	gock.New("https://apps-apis.google.com").
+		Get("/a/feeds/compliance/audit/mail/monitor/example.com/abhishek/namrata").
+		Reply(200).
+		XML(monitorXML)
+	var body string
+	gock.New("https://apps-apis.google.com").
+		Post("/a/feeds/compliance/audit/mail/monitor/example.com/abhishek").
+		AddMatcher(func(req *http.Request, ereq *gock.Request) (bool, error) {
+			b, err := ioutil.ReadAll(req.Body)
+			body = string(b)
+			return true, err
+		}).
+		Reply(200).
+		XML(monitorXML)
+
+	beginDate := time.Date(2116, time.January, 1, 0, 0, 0, 0, time.UTC)
+	endDate := time.Date(2116, time.October, 30, 14, 59, 0, 0, time.UTC)
+	_, _, err := newTestService().MailMonitor.Patch("example.com", "abhishek", "namrata", MailMonitorPatch{BeginDate: &beginDate, EndDate: &endDate})
+	if err != nil {
+		t.Errorf("Expected nil but got %v", err)
+	}
+	expected := `<apps:property name="beginDate" value="2116-01-01 00:00"></apps:property>`
+	if !strings.Contains(body, expected) {
+		t.Errorf(`Expected "%v" in "%v"`, expected, body)
+	}
+}
+
+func TestMailMonitorServicePatchNotFound(t *testing.T) {
+	defer gock.Off()
+	gock.New("https://apps-apis.google.com").
+		Get("/a/feeds/compliance/audit/mail/monitor/example.com/abhishek/namrata").
+		Reply(404)
+
+	before, after, err := newTestService().MailMonitor.Patch("example.com", "abhishek", "namrata", MailMonitorPatch{})
+	if _, ok := err.(*MailMonitorNotFoundError); !ok {
+		t.Errorf("Expected *MailMonitorNotFoundError but got %T", err)
+	}
+	if before != nil || after != nil {
+		t.Errorf("Expected nil but got %v %v", before, after)
+	}
+}
+
+func TestMailMonitorServicePatchUpdateError(t *testing.T) {
+	defer gock.Off()
+	gock.New("https://apps-apis.google.com").
+		Get("/a/feeds/compliance/audit/mail/monitor/example.com/abhishek/namrata").
+		Reply(200).
+		XML(monitorXML)
+	gock.New("https://apps-apis.google.com").
+		Post("/a/feeds/compliance/audit/mail/monitor/example.com/abhishek").
+		Reply(400).
+		BodyString("Omg")
+
+	endDate := time.Date(2116, time.October, 30, 14, 59, 0, 0, time.UTC)
+	before, after, err := newTestService().MailMonitor.Patch("example.com", "abhishek", "namrata", MailMonitorPatch{EndDate: &endDate})
+	expected := "400 Bad Request: Omg"
+	if err == nil || err.Error() != expected {
+		t.Errorf(`Expected "%v" but got "%v"`, expected, err)
+	}
+	if before == nil {
+		t.Errorf("Expected not nil but got %v", before)
+	}
+	if after != nil {
+		t.Errorf("Expected nil but got %v", after)
+	}
+}
+
+func TestMailMonitorServiceUpdateValidationError(t *testing.T) {
+	endDate := time.Date(2016, time.October, 30, 14, 59, 0, 0, time.UTC)
+	m, err := newTestService().MailMonitor.Update("example.com", "abhishek", "", endDate, MailMonitorLevels{})
+	if m != nil {
+		t.Errorf("Expected nil but got %v", m)
+	}
+	if _, ok := err.(*ValidationError); !ok {
+		t.Errorf("Expected *ValidationError but got %T", err)
+	}
+	expected := "invalid email monitor: destUserName is empty, endDate is in the past, monitorLevels are all none"
+	if err.Error() != expected {
+		t.Errorf(`Expected "%v" but got "%v"`, expected, err)
+	}
+}
+
+func TestMailMonitorServiceBaseURL(t *testing.T) {
+	defer gock.Off()
+	gock.New("https://proxy.example.com").
+		Get("/audit/mail/monitor/example.com/abhishek").
+		MatchHeader("Authorization", "Bearer test").
+		Reply(200).
+		XML(monitorsXML)
+	gock.New("https://proxy.example.com").
+		Post("/audit/mail/monitor/example.com/abhishek").
+		Reply(200).
+		XML(monitorXML)
+
+	svc := newTestService(WithBaseURL("https://proxy.example.com/audit/"))
+	m, err := svc.MailMonitor.List("example.com", "abhishek")
+	if err != nil {
+		t.Fatalf("Expected nil but got %v", err)
+	}
+	_TestMonitors(m, t)
+	expected := "https://proxy.example.com/audit/mail/monitor/example.com/abhishek"
+	if m[0].URL() != expected {
+		t.Errorf(`Expected "%v" but got "%v"`, expected, m[0].URL())
+	}
+	endDate := time.Date(2116, time.October, 30, 14, 59, 0, 0, time.UTC)
+	monitor, err := svc.MailMonitor.Update("example.com", "abhishek", "namrata", endDate, MailMonitorLevels{Chat: HeaderOnlyLevel})
+	if err != nil {
+		t.Errorf("Expected nil but got %v", err)
+	}
+	if monitor.URL() != expected {
+		t.Errorf(`Expected "%v" but got "%v"`, expected, monitor.URL())
+	}
+}
+
 func TestMailMonitorServiceUpdateHTTPError(t *testing.T) {
 	defer gock.Off()
 	gock.New("https://apps-apis.google.com").
@@ -140,7 +395,7 @@ func TestMailMonitorServiceUpdateHTTPError(t *testing.T) {
 		ReplyError(errors.New("Error!"))
 
 	monitor2, err := updateEmailMonitor()
-	expected := "Post https://apps-apis.google.com/a/feeds/compliance/audit/mail/monitor/example.com/abhishek: Error!"
+	expected := `Post "https://apps-apis.google.com/a/feeds/compliance/audit/mail/monitor/example.com/abhishek": Error!`
 	if err.Error() != expected {
 		t.Errorf(`Expected "%v" but got "%v"`, expected, err)
 	}
@@ -158,7 +413,7 @@ func TestMailMonitorServiceListHTTPError(t *testing.T) {
 		ReplyError(errors.New("Error!"))
 
 	monitor2, err := listEmailMonitors()
-	expected := "Get https://apps-apis.google.com/a/feeds/compliance/audit/mail/monitor/example.com/abhishek: Error!"
+	expected := `Get "https://apps-apis.google.com/a/feeds/compliance/audit/mail/monitor/example.com/abhishek": Error!`
 	if err.Error() != expected {
 		t.Errorf(`Expected "%v" but got "%v"`, expected, err)
 	}
@@ -175,7 +430,7 @@ func TestMailMonitorServiceDisableHTTPError(t *testing.T) {
 		MatchHeader("User-Agent", "google-api-go-client/0.5").
 		ReplyError(errors.New("Error!"))
 
-	expected := "Error!"
+	expected := `Delete "https://apps-apis.google.com/a/feeds/compliance/audit/mail/monitor/example.com/abhishek/namrata": Error!`
 	err := disableEmailMonitors()
 	if err.Error() != expected {
 		t.Errorf(`Expected "%v" but got "%v"`, expected, err)
@@ -193,7 +448,7 @@ func TestMailMonitorServiceUpdateHTTPErrorResponse(t *testing.T) {
 		BodyString("Omg")
 
 	monitor2, err := updateEmailMonitor()
-	expected := "Omg"
+	expected := "400 Bad Request: Omg"
 	if err.Error() != expected {
 		t.Errorf(`Expected "%v" but got "%v"`, expected, err)
 	}
@@ -212,7 +467,7 @@ func TestMailMonitorServiceListHTTPErrorResponse(t *testing.T) {
 		BodyString("Omg")
 
 	monitor2, err := listEmailMonitors()
-	expected := "Omg"
+	expected := "400 Bad Request: Omg"
 	if err.Error() != expected {
 		t.Errorf(`Expected "%v" but got "%v"`, expected, err)
 	}
@@ -230,9 +485,70 @@ func TestMailMonitorServiceDisableHTTPErrorResponse(t *testing.T) {
 		Reply(400).
 		BodyString("Omg")
 
-	expected := "Omg"
+	expected := "400 Bad Request: Omg"
 	err := disableEmailMonitors()
 	if err.Error() != expected {
 		t.Errorf(`Expected "%v" but got "%v"`, expected, err)
 	}
 }
+
+func TestMailMonitorServiceContextCanceled(t *testing.T) {
+	defer gock.Off()
+	gock.New("https://apps-apis.google.com").
+		Persist().
+		Reply(200)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	svc := newTestService()
+	endDate := time.Date(2116, time.October, 30, 14, 59, 0, 0, time.UTC)
+	monitor, err1 := svc.MailMonitor.UpdateContext(ctx, "example.com", "abhishek", "namrata", endDate, MailMonitorLevels{Chat: HeaderOnlyLevel})
+	monitors, err2 := svc.MailMonitor.ListContext(ctx, "example.com", "abhishek")
+	err3 := svc.MailMonitor.DisableContext(ctx, "example.com", "abhishek", "namrata")
+	for _, err := range []error{err1, err2, err3} {
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf(`Expected "%v" but got "%v"`, context.Canceled, err)
+		}
+	}
+	if monitor != nil || monitors != nil {
+		t.Errorf("Expected nil but got %v %v", monitor, monitors)
+	}
+}
+
+type rewriteTransport struct {
+	url *url.URL
+}
+
+func (rt rewriteTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req.URL.Scheme = rt.url.Scheme
+	req.URL.Host = rt.url.Host
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+func TestMailMonitorServiceContextAbortsBodyRead(t *testing.T) {
+	done := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(200)
+		w.Write([]byte("<feed"))
+		w.(http.Flusher).Flush()
+		select {
+		case <-done:
+		case <-time.After(10 * time.Second):
+		}
+	}))
+	defer server.Close()
+	defer close(done)
+	u, _ := url.Parse(server.URL)
+	svc, _ := New(&http.Client{Transport: rewriteTransport{u}})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	_, err := svc.MailMonitor.ListContext(ctx, "example.com", "abhishek")
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf(`Expected "%v" but got "%v"`, context.DeadlineExceeded, err)
+	}
+	if time.Since(start) > 5*time.Second {
+		t.Errorf("Expected body read to be aborted but took %v", time.Since(start))
+	}
+}
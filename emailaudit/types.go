@@ -8,8 +8,8 @@ import (
 )
 
 const (
-	timeFormat = "2006-01-02 15:04"
-	baseURL    = "https://apps-apis.google.com/a/feeds/compliance/audit/mail/monitor"
+	timeFormat      = "2006-01-02 15:04"
+	mailMonitorPath = "mail/monitor"
 )
 
 // MailMonitor MailMonitor
@@ -21,6 +21,63 @@ type MailMonitor struct {
 	EndDate        *time.Time
 	MonitorLevels  MailMonitorLevels
 	Updated        *time.Time
+	RequestID      string
+	SelfLink       string
+	EditLink       string
+	// Extra holds properties of the entry not mapped to other fields
+	Extra map[string]string
+
+	baseURL string
+}
+
+// MailMonitorNotFoundError is returned when an email monitor does not exist
+type MailMonitorNotFoundError struct {
+	DomainName     string
+	SourceUserName string
+	DestUserName   string
+	// Err is the APIError of the response
+	Err error
+}
+
+func (e *MailMonitorNotFoundError) Error() string {
+	return fmt.Sprintf("email monitor of %v@%v to %v does not exist", e.SourceUserName, e.DomainName, e.DestUserName)
+}
+
+// Unwrap returns the APIError of the response
+func (e *MailMonitorNotFoundError) Unwrap() error {
+	return e.Err
+}
+
+// MailMonitorPatch holds the fields changed by Patch. Nil fields are left as they are.
+type MailMonitorPatch struct {
+	BeginDate     *time.Time
+	EndDate       *time.Time
+	IncomingEmail *MailMonitorLevel
+	OutgoingEmail *MailMonitorLevel
+	Draft         *MailMonitorLevel
+	Chat          *MailMonitorLevel
+}
+
+func (p MailMonitorPatch) apply(m MailMonitor) MailMonitor {
+	if p.BeginDate != nil {
+		m.BeginDate = p.BeginDate
+	}
+	if p.EndDate != nil {
+		m.EndDate = p.EndDate
+	}
+	if p.IncomingEmail != nil {
+		m.MonitorLevels.IncomingEmail = *p.IncomingEmail
+	}
+	if p.OutgoingEmail != nil {
+		m.MonitorLevels.OutgoingEmail = *p.OutgoingEmail
+	}
+	if p.Draft != nil {
+		m.MonitorLevels.Draft = *p.Draft
+	}
+	if p.Chat != nil {
+		m.MonitorLevels.Chat = *p.Chat
+	}
+	return m
 }
 
 // MailMonitorLevels MailMonitorLevels
@@ -31,6 +88,21 @@ type MailMonitorLevels struct {
 	Chat          MailMonitorLevel
 }
 
+// IsNone reports whether the level is NoneLevel or ExplicitNoneLevel.
+// Levels read from the API are ExplicitNoneLevel for NONE and NoneLevel when omitted.
+func (l MailMonitorLevel) IsNone() bool {
+	return l == NoneLevel || l == ExplicitNoneLevel
+}
+
+func (l MailMonitorLevels) isNone() bool {
+	for _, level := range []MailMonitorLevel{l.IncomingEmail, l.OutgoingEmail, l.Draft, l.Chat} {
+		if !level.IsNone() {
+			return false
+		}
+	}
+	return true
+}
+
 // NewMailMonitor returns new MailMonitor
 func NewMailMonitor(domainName string,
 	sourceUserName string,
@@ -48,6 +120,61 @@ func NewMailMonitor(domainName string,
 	return m
 }
 
+// ValidationError lists every invalid field of a MailMonitor
+type ValidationError struct {
+	Problems []FieldProblem
+}
+
+// FieldProblem FieldProblem
+type FieldProblem struct {
+	Field   string
+	Message string
+}
+
+func (e *ValidationError) Error() string {
+	var problems []string
+	for _, p := range e.Problems {
+		problems = append(problems, p.Field+" "+p.Message)
+	}
+	return "invalid email monitor: " + strings.Join(problems, ", ")
+}
+
+// Validate returns ValidationError if the monitor would be rejected by the API
+func (req *MailMonitor) Validate() error {
+	return req.validate(time.Now())
+}
+
+func (req *MailMonitor) validate(now time.Time) error {
+	var problems []FieldProblem
+	add := func(field string, message string) {
+		problems = append(problems, FieldProblem{Field: field, Message: message})
+	}
+	if req.DomainName == "" {
+		add("domainName", "is empty")
+	}
+	if req.SourceUserName == "" {
+		add("sourceUserName", "is empty")
+	}
+	if req.DestUserName == "" {
+		add("destUserName", "is empty")
+	}
+	if req.EndDate == nil {
+		add("endDate", "is required")
+	} else if req.EndDate.Before(now) {
+		add("endDate", "is in the past")
+	}
+	if req.BeginDate != nil && req.EndDate != nil && req.BeginDate.After(*req.EndDate) {
+		add("beginDate", "is after endDate")
+	}
+	if req.MonitorLevels.isNone() {
+		add("monitorLevels", "are all none")
+	}
+	if len(problems) > 0 {
+		return &ValidationError{Problems: problems}
+	}
+	return nil
+}
+
 func (req *MailMonitor) monitorWriteProperties() monitorWriteProperties {
 	m := monitorWriteProperties{}
 	m.addProperty("destUserName", req.DestUserName)
@@ -72,17 +199,12 @@ func (req *MailMonitor) monitorWriteProperties() monitorWriteProperties {
 }
 
 func (req *MailMonitor) toXML() []byte {
-	x, _ := xml.MarshalIndent(req.monitorWriteProperties(), "", "  ")
-	xstr := string(x)
-	xstr = strings.Replace(xstr, `<entry xmlns="http://www.w3.org/2005/Atom">`,
-		`<atom:entry xmlns:atom="http://www.w3.org/2005/Atom" xmlns:apps="http://schemas.google.com/apps/2006">`, 1)
-	xstr = strings.Replace(xstr, `</entry>`, `</atom:entry>`, 1)
-	return []byte(xstr)
+	return req.monitorWriteProperties().toXML()
 }
 
 // URL returns URL
 func (req *MailMonitor) URL() string {
-	return fmt.Sprintf("%v/%v/%v", baseURL, req.DomainName, req.SourceUserName)
+	return fmt.Sprintf("%v/%v/%v", endpoint(req.baseURL, mailMonitorPath), req.DomainName, req.SourceUserName)
 }
 
 func monitorFromXML(data []byte) (*MailMonitor, error) {
@@ -123,13 +245,21 @@ func (m monitorReadProperties) toMonitor() MailMonitor {
 			d, _ := time.Parse(timeFormat, p.Value)
 			mm.EndDate = &d
 			break
+		case "requestId":
+			mm.RequestID = p.Value
+			break
+		case "incomingEmailMonitorLevel", "outgoingEmailMonitorLevel", "draftMonitorLevel", "chatMonitorLevel":
+			break
+		default:
+			if mm.Extra == nil {
+				mm.Extra = map[string]string{}
+			}
+			mm.Extra[p.Name] = p.Value
 		}
 	}
-	if strings.HasPrefix(m.ID, baseURL) {
-		urlparts := strings.Split(strings.Replace(m.ID, baseURL+"/", "", 1), "/")
-		mm.DomainName = urlparts[0]
-		mm.SourceUserName = urlparts[1]
-	}
+	mm.SelfLink = m.link("self")
+	mm.EditLink = m.link("edit")
+	mm.DomainName, mm.SourceUserName = m.domainAndUser(mailMonitorPath, "", "")
 	mm.Updated = m.Updated
 	return mm
 }
@@ -138,9 +268,6 @@ func (m monitorReadProperties) toMonitorLevels() MailMonitorLevels {
 	ret := MailMonitorLevels{}
 	for _, p := range m.AppProperties {
 		l := MailMonitorLevel(p.Value)
-		if p.Value == "NONE" {
-			l = NoneLevel
-		}
 		switch p.Name {
 		case "incomingEmailMonitorLevel":
 			ret.IncomingEmail = l
@@ -162,6 +289,16 @@ func (m monitorReadProperties) toMonitorLevels() MailMonitorLevels {
 type monitorReadListProperties struct {
 	XMLName xml.Name                `xml:"http://www.w3.org/2005/Atom feed,omitempty"`
 	Entries []monitorReadProperties `xml:"entry"`
+	Links   []link                  `xml:"link"`
+}
+
+func (l monitorReadListProperties) nextURL() string {
+	for _, link := range l.Links {
+		if link.Rel == "next" {
+			return link.Href
+		}
+	}
+	return ""
 }
 
 type monitorReadProperties struct {
@@ -169,7 +306,26 @@ type monitorReadProperties struct {
 	ID            string        `xml:"id,omitempty"`
 	Updated       *time.Time    `xml:"updated,omitempty"`
 	AppProperties []appProperty `xml:"http://schemas.google.com/apps/2006 property"`
-	Links         []link
+	Links         []link        `xml:"link"`
+}
+
+// domainAndUser parses the domain and user following path in the entry ID or its self or edit link
+func (m monitorReadProperties) domainAndUser(path string, userEmailAddress string, requestID string) (string, string) {
+	for _, id := range []string{m.ID, m.link("self"), m.link("edit")} {
+		if strings.Contains(id, "/"+path+"/") {
+			return domainAndUserFromID(id, path, userEmailAddress, requestID)
+		}
+	}
+	return domainAndUserFromID(m.ID, path, userEmailAddress, requestID)
+}
+
+func (m monitorReadProperties) link(rel string) string {
+	for _, link := range m.Links {
+		if link.Rel == rel {
+			return link.Href
+		}
+	}
+	return ""
 }
 
 type monitorWriteProperties struct {
@@ -177,6 +333,15 @@ type monitorWriteProperties struct {
 	AppProperties []appProperty `xml:"apps:property"`
 }
 
+func (m monitorWriteProperties) toXML() []byte {
+	x, _ := xml.MarshalIndent(m, "", "  ")
+	xstr := string(x)
+	xstr = strings.Replace(xstr, `<entry xmlns="http://www.w3.org/2005/Atom">`,
+		`<atom:entry xmlns:atom="http://www.w3.org/2005/Atom" xmlns:apps="http://schemas.google.com/apps/2006">`, 1)
+	xstr = strings.Replace(xstr, `</entry>`, `</atom:entry>`, 1)
+	return []byte(xstr)
+}
+
 func (m *monitorWriteProperties) addProperty(name string, value interface{}) {
 	if date, ok := value.(*time.Time); ok {
 		value = date.UTC().Format(timeFormat)
@@ -1,6 +1,8 @@
 package emailaudit
 
 import (
+	"reflect"
+	"strings"
 	"testing"
 	"time"
 )
@@ -67,6 +69,87 @@ func TestMailMonitorToXML(t *testing.T) {
 	}
 }
 
+func TestMailMonitorLevelsRoundTrip(t *testing.T) {
+	endDate := time.Date(2116, time.October, 30, 14, 59, 0, 0, time.UTC)
+	for _, test := range []struct {
+		level    MailMonitorLevel
+		property string
+		read     MailMonitorLevel
+	}{
+		{NoneLevel, "", NoneLevel},
+		{ExplicitNoneLevel, `<apps:property name="chatMonitorLevel" value="NONE"></apps:property>`, ExplicitNoneLevel},
+		{FullMessageLevel, `<apps:property name="chatMonitorLevel" value="FULL_MESSAGE"></apps:property>`, FullMessageLevel},
+	} {
+		m := NewMailMonitor("example.com", "abhishek", "namrata", endDate, MailMonitorLevels{
+			IncomingEmail: HeaderOnlyLevel,
+			Chat:          test.level,
+		})
+		x := string(m.toXML())
+		if test.property == "" && strings.Contains(x, "chatMonitorLevel") {
+			t.Errorf(`Expected no chatMonitorLevel but got "%v"`, x)
+		}
+		if test.property != "" && !strings.Contains(x, test.property) {
+			t.Errorf(`Expected "%v" in "%v"`, test.property, x)
+		}
+		x = strings.Replace(x, "<atom:entry", `<entry xmlns="http://www.w3.org/2005/Atom"`, 1)
+		x = strings.Replace(x, "</atom:entry>", "</entry>", 1)
+		x = strings.Replace(x, "apps:property", `property xmlns="http://schemas.google.com/apps/2006"`, -1)
+		x = strings.Replace(x, `</property xmlns="http://schemas.google.com/apps/2006">`, "</property>", -1)
+		read, err := monitorFromXML([]byte(x))
+		if err != nil {
+			t.Errorf("Expected nil but got %v", err)
+			continue
+		}
+		for _, test := range []struct {
+			actual   interface{}
+			expected interface{}
+		}{
+			{read.MonitorLevels.Chat, test.read},
+			{read.MonitorLevels.Chat.IsNone(), test.level.IsNone()},
+			{read.MonitorLevels.IncomingEmail, HeaderOnlyLevel},
+			{read.MonitorLevels.Draft, NoneLevel},
+		} {
+			if test.actual != test.expected {
+				t.Errorf(`Expected "%v" but got "%v"`, test.expected, test.actual)
+			}
+		}
+	}
+}
+
+func TestMailMonitorValidate(t *testing.T) {
+	future := time.Now().Add(24 * time.Hour)
+	past := time.Now().Add(-24 * time.Hour)
+	later := future.Add(time.Hour)
+	levels := MailMonitorLevels{Chat: HeaderOnlyLevel}
+	for _, test := range []struct {
+		monitor  MailMonitor
+		expected string
+	}{
+		{NewMailMonitor("example.com", "abhishek", "namrata", future, levels), ""},
+		{NewMailMonitor("example.com", "abhishek", "", future, levels), "invalid email monitor: destUserName is empty"},
+		{NewMailMonitor("example.com", "abhishek", "namrata", past, levels), "invalid email monitor: endDate is in the past"},
+		{NewMailMonitor("example.com", "abhishek", "namrata", future, MailMonitorLevels{}), "invalid email monitor: monitorLevels are all none"},
+		{NewMailMonitor("example.com", "abhishek", "namrata", future, MailMonitorLevels{Chat: ExplicitNoneLevel}), "invalid email monitor: monitorLevels are all none"},
+		{MailMonitor{DomainName: "example.com", SourceUserName: "abhishek", DestUserName: "namrata", BeginDate: &later, EndDate: &future, MonitorLevels: levels}, "invalid email monitor: beginDate is after endDate"},
+		{MailMonitor{}, "invalid email monitor: domainName is empty, sourceUserName is empty, destUserName is empty, endDate is required, monitorLevels are all none"},
+	} {
+		err := test.monitor.Validate()
+		actual := ""
+		if err != nil {
+			actual = err.Error()
+		}
+		if actual != test.expected {
+			t.Errorf(`Expected "%v" but got "%v"`, test.expected, actual)
+		}
+	}
+	m := NewMailMonitor("example.com", "abhishek", "", past, levels)
+	err := m.Validate().(*ValidationError)
+	expected := []FieldProblem{{Field: "destUserName", Message: "is empty"}, {Field: "endDate", Message: "is in the past"}}
+	if !reflect.DeepEqual(err.Problems, expected) {
+		t.Errorf(`Expected "%v" but got "%v"`, expected, err.Problems)
+	}
+}
+
 func TestMailMonitorURL(t *testing.T) {
 	m := MailMonitor{
 		SourceUserName: "src",
@@ -79,6 +162,40 @@ func TestMailMonitorURL(t *testing.T) {
 	}
 }
 
+func TestMailMonitorURLWithBaseURL(t *testing.T) {
+	m := MailMonitor{
+		SourceUserName: "src",
+		DomainName:     "example.com",
+		baseURL:        "http://localhost:8080/audit/",
+	}
+	expected := "http://localhost:8080/audit/mail/monitor/example.com/src"
+	actual := m.URL()
+	if expected != actual {
+		t.Errorf(`Expected "%v" but got "%v"`, expected, actual)
+	}
+}
+
+func TestMailMonitorFromXMLDomainAndUser(t *testing.T) {
+	for _, test := range []struct {
+		xml            string
+		domainName     string
+		sourceUserName string
+	}{
+		{strings.Replace(monitorXML, "https://apps-apis.google.com/a/feeds/compliance/audit", "http://localhost:8080/audit", -1), "example.com", "abhishek"},
+		{strings.Replace(monitorXML, "<id>https://apps-apis.google.com/a/feeds/compliance/audit/mail/monitor/example.com/abhishek/namrata</id>", "<id>tag:example.com,2009:53156</id>", 1), "example.com", "abhishek"},
+		{`<entry xmlns='http://www.w3.org/2005/Atom'><id>tag:example.com,2009:53156</id></entry>`, "", ""},
+	} {
+		m, err := monitorFromXML([]byte(test.xml))
+		if err != nil {
+			t.Errorf("Expected nil but got %v", err)
+			continue
+		}
+		if m.DomainName != test.domainName || m.SourceUserName != test.sourceUserName {
+			t.Errorf(`Expected "%v/%v" but got "%v/%v"`, test.domainName, test.sourceUserName, m.DomainName, m.SourceUserName)
+		}
+	}
+}
+
 const monitorXML = `<entry xmlns='http://www.w3.org/2005/Atom' xmlns:apps='http://schemas.google.com/apps/2006'>
   <id>https://apps-apis.google.com/a/feeds/compliance/audit/mail/monitor/example.com/abhishek/namrata</id>
   <updated>2009-08-20T00:28:57.319Z</updated>
@@ -116,6 +233,10 @@ func _TestMonitor(m *MailMonitor, t *testing.T) {
 		{m.EndDate.UnixNano(), time.Date(2016, time.October, 30, 14, 59, 0, 0, time.UTC).UnixNano()},
 		{m.BeginDate.UnixNano(), time.Date(2016, time.August, 31, 15, 0, 0, 0, time.UTC).UnixNano()},
 		{m.Updated.UnixNano(), time.Date(2009, time.August, 20, 0, 28, 57, 319000000, time.UTC).UnixNano()},
+		{m.RequestID, ""},
+		{m.SelfLink, "https://apps-apis.google.com/a/feeds/compliance/audit/mail/monitor/example.com/abhishek/namrata"},
+		{m.EditLink, "https://apps-apis.google.com/a/feeds/compliance/audit/mail/monitor/example.com/abhishek/namrata"},
+		{len(m.Extra), 0},
 	} {
 		if test.actual != test.expected {
 			t.Errorf(`Expected "%v" but got "%v"`, test.expected, test.actual)
@@ -168,8 +289,9 @@ func _TestMonitors(m []MailMonitor, t *testing.T) {
 		expected interface{}
 	}{
 		{len(m), 2},
-		{m[0].MonitorLevels.Chat, NoneLevel},
-		{m[0].MonitorLevels.Draft, NoneLevel},
+		{m[0].MonitorLevels.Chat, ExplicitNoneLevel},
+		{m[0].MonitorLevels.Draft, ExplicitNoneLevel},
+		{m[0].MonitorLevels.Chat.IsNone(), true},
 		{m[0].MonitorLevels.IncomingEmail, FullMessageLevel},
 		{m[0].MonitorLevels.OutgoingEmail, FullMessageLevel},
 		{m[0].DestUserName, "namrata"},
@@ -178,6 +300,9 @@ func _TestMonitors(m []MailMonitor, t *testing.T) {
 		{m[0].BeginDate.String(), time.Date(2009, time.June, 15, 0, 0, 0, 0, time.UTC).String()},
 		{m[0].EndDate.String(), time.Date(2009, time.June, 30, 23, 20, 0, 0, time.UTC).String()},
 		{m[0].Updated.String(), time.Date(2009, time.April, 17, 15, 29, 21, 64000000, time.UTC).String()},
+		{m[0].RequestID, "53156"},
+		{m[0].SelfLink, "https://apps-apis.google.com/a/feeds/compliance/audit/mail/monitor/example.com/abhishek/namrata"},
+		{m[0].EditLink, "https://apps-apis.google.com/feeds/compliance/audit/mail/monitor/example.com/abhishek/namrata"},
 		{m[1].MonitorLevels.Chat, FullMessageLevel},
 		{m[1].MonitorLevels.Draft, FullMessageLevel},
 		{m[1].MonitorLevels.IncomingEmail, FullMessageLevel},
@@ -188,6 +313,8 @@ func _TestMonitors(m []MailMonitor, t *testing.T) {
 		{m[1].BeginDate.String(), time.Date(2009, time.June, 20, 0, 0, 0, 0, time.UTC).String()},
 		{m[1].EndDate.String(), time.Date(2009, time.July, 30, 23, 20, 0, 0, time.UTC).String()},
 		{m[1].Updated.String(), time.Date(2009, time.May, 17, 15, 29, 21, 64000000, time.UTC).String()},
+		{m[1].RequestID, "22405"},
+		{m[1].EditLink, "https://apps-apis.google.com/a/feeds/compliance/audit/mail/monitor/example.com/abhishek/joe"},
 	} {
 		if test.actual != test.expected {
 			t.Errorf(`Expected "%v" but got "%v"`, test.expected, test.actual)
@@ -203,6 +330,30 @@ func TestMailMonitorsFromXML(t *testing.T) {
 	_TestMonitors(m, t)
 }
 
+func TestMailMonitorFromXMLExtra(t *testing.T) {
+	x := strings.Replace(monitorXML, `<apps:property name="destUserName"`,
+		`<apps:property name="requestId" value="53156"></apps:property>
+  <apps:property name="status" value="ACTIVE"></apps:property>
+  <apps:property name="destUserName"`, 1)
+	m, err := monitorFromXML([]byte(x))
+	if err != nil {
+		t.Errorf("Expected nil but got %v", err)
+	}
+	for _, test := range []struct {
+		actual   interface{}
+		expected interface{}
+	}{
+		{m.RequestID, "53156"},
+		{len(m.Extra), 1},
+		{m.Extra["status"], "ACTIVE"},
+		{m.DestUserName, "namrata"},
+	} {
+		if test.actual != test.expected {
+			t.Errorf(`Expected "%v" but got "%v"`, test.expected, test.actual)
+		}
+	}
+}
+
 func TestMonitorFromXMLError(t *testing.T) {
 	x := []byte("<foo />")
 	m, err := monitorFromXML([]byte(x))
@@ -0,0 +1,18 @@
+module github.com/ngs/go-google-email-audit-api
+
+go 1.23.0
+
+require (
+	github.com/ProtonMail/go-crypto v1.5.2
+	golang.org/x/oauth2 v0.23.0
+	google.golang.org/api v0.203.0
+	gopkg.in/h2non/gock.v1 v1.1.2
+)
+
+require (
+	cloud.google.com/go/compute/metadata v0.5.2 // indirect
+	github.com/cloudflare/circl v1.6.3 // indirect
+	github.com/h2non/parth v0.0.0-20190131123155-b4df798d6542 // indirect
+	golang.org/x/crypto v0.41.0 // indirect
+	golang.org/x/sys v0.35.0 // indirect
+)